@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// checkpoint records the dates already exported so an interrupted run can resume
+type checkpoint struct {
+	Completed []string `json:"completed"`
+
+	// Empty lists completed dates that had no readings, and so no CSV rows
+	Empty []string `json:"empty,omitempty"`
+
+	// RawCSVSize is the raw CSV's size when the checkpoint was last saved.
+	// Rows past it belong to a day that was not recorded and are discarded.
+	RawCSVSize *int64 `json:"raw_csv_size,omitempty"`
+}
+
+// loadCheckpoint reads a checkpoint file, returning an empty checkpoint if it does not exist
+func loadCheckpoint(filename string) (*checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return &checkpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", filename, err)
+	}
+	return &cp, nil
+}
+
+// save writes the checkpoint file
+func (cp *checkpoint) save(filename string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// has reports whether the date has already been exported
+func (cp *checkpoint) has(date string) bool {
+	for _, d := range cp.Completed {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
+
+// add marks the date as exported
+func (cp *checkpoint) add(date string) {
+	if !cp.has(date) {
+		cp.Completed = append(cp.Completed, date)
+	}
+}
+
+// addEmpty marks the date as exported without readings
+func (cp *checkpoint) addEmpty(date string) {
+	cp.add(date)
+	for _, d := range cp.Empty {
+		if d == date {
+			return
+		}
+	}
+	cp.Empty = append(cp.Empty, date)
+}
+
+// isEmpty reports whether the date was exported without readings
+func (cp *checkpoint) isEmpty(date string) bool {
+	for _, d := range cp.Empty {
+		if d == date {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchWithCheckpoint fetches the range one day at a time, skipping days recorded
+// in the checkpoint, appending each new day to the raw CSV and recording it.
+// Completed days are read back from the raw CSV; one recorded with readings
+// but missing from the CSV is fetched again. Every day in the range is
+// returned in date order. If onDay is set it receives each day in that order as
+// soon as it is available.
+func fetchWithCheckpoint(ctx context.Context, client *growatt.Client, serial string, from, to time.Time, tz, rawCSVFile, checkpointFile string, onDay func(*growatt.PowerData)) ([]growatt.PowerData, error) {
+	cp, err := loadCheckpoint(checkpointFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncRawCSV(cp, rawCSVFile, checkpointFile); err != nil {
+		return nil, err
+	}
+
+	existing, err := readRawCSV(rawCSVFile)
+	if err != nil {
+		return nil, err
+	}
+	completed := make(map[string]growatt.PowerData, len(existing))
+	for _, day := range existing {
+		completed[day.Date] = day
+	}
+
+	var result []growatt.PowerData
+	total := rangeDays(from, to)
+	done := 0
+	for current := from; !current.After(to); current = current.AddDate(0, 0, 1) {
+		done++
+		dateStr := current.Format("2006-01-02")
+
+		var data *growatt.PowerData
+		if day, ok := completed[dateStr]; ok && cp.has(dateStr) {
+			data = &day
+		} else if cp.isEmpty(dateStr) {
+			// Days without readings have no rows in the CSV; keep them as
+			// empty days like an uncheckpointed fetch does
+			data = &growatt.PowerData{Date: dateStr}
+		} else {
+			data, err = client.GetMINInverterHistory(ctx, serial, current, tz)
+			if err != nil {
+				return nil, fmt.Errorf("fetching power for %s: %w", dateStr, err)
+			}
+
+			if err := appendRawCSV(rawCSVFile, []growatt.PowerData{*data}); err != nil {
+				return nil, fmt.Errorf("writing raw CSV: %w", err)
+			}
+
+			size, err := fileSize(rawCSVFile)
+			if err != nil {
+				return nil, fmt.Errorf("writing raw CSV: %w", err)
+			}
+			if len(data.Powers) == 0 {
+				cp.addEmpty(dateStr)
+			} else {
+				cp.add(dateStr)
+			}
+			cp.RawCSVSize = &size
+			if err := cp.save(checkpointFile); err != nil {
+				return nil, fmt.Errorf("saving checkpoint: %w", err)
+			}
+
+			if !quiet {
+				printProgress(done, total, current)
+			}
+		}
+
+		result = append(result, *data)
+		if onDay != nil {
+			onDay(data)
+		}
+	}
+
+	return result, nil
+}
+
+// syncRawCSV truncates rows appended after the checkpoint was last saved, which
+// a crash between the two writes leaves behind and a resume would duplicate.
+// It refuses a raw CSV that a new checkpoint did not write, or one shorter than
+// the checkpoint recorded. A checkpoint from before sizes were recorded adopts
+// the file's current size.
+func syncRawCSV(cp *checkpoint, rawCSVFile, checkpointFile string) error {
+	size, err := fileSize(rawCSVFile)
+	if err != nil {
+		return fmt.Errorf("reading raw CSV: %w", err)
+	}
+
+	if cp.RawCSVSize == nil {
+		if len(cp.Completed) == 0 && size > 0 {
+			return fmt.Errorf("raw CSV %s already exists and was not written under checkpoint %s; move it aside to start a new export", rawCSVFile, checkpointFile)
+		}
+		cp.RawCSVSize = &size
+		if err := cp.save(checkpointFile); err != nil {
+			return fmt.Errorf("saving checkpoint: %w", err)
+		}
+		return nil
+	}
+
+	switch {
+	case size > *cp.RawCSVSize:
+		if err := os.Truncate(rawCSVFile, *cp.RawCSVSize); err != nil {
+			return fmt.Errorf("truncating raw CSV: %w", err)
+		}
+	case size < *cp.RawCSVSize:
+		return fmt.Errorf("raw CSV %s is smaller than checkpoint %s recorded; remove the checkpoint to start over", rawCSVFile, checkpointFile)
+	}
+	return nil
+}
+
+// fileSize returns the size of a file, or 0 if it does not exist
+func fileSize(filename string) (int64, error) {
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// appendRawCSV appends power data to a raw CSV, writing the header if the file is new
+func appendRawCSV(filename string, data []growatt.PowerData) error {
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		if err := w.Write([]string{"date", "time", "power_watts"}); err != nil {
+			return err
+		}
+	}
+
+	if err := writeRawRows(w, data); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// readRawCSV reads a raw CSV back into per-day power data, in file order
+func readRawCSV(filename string) ([]growatt.PowerData, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+
+	// Skip header
+	if _, err := r.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []growatt.PowerData
+	index := make(map[string]int)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			continue
+		}
+
+		power, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing power %q: %w", record[2], err)
+		}
+
+		i, ok := index[record[0]]
+		if !ok {
+			i = len(result)
+			index[record[0]] = i
+			result = append(result, growatt.PowerData{Date: record[0]})
+		}
+		result[i].Powers = append(result[i].Powers, growatt.PowerDataPoint{
+			Time:  record[1],
+			Power: power,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// Missing file yields an empty checkpoint
+	cp, err := loadCheckpoint(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cp.Completed) != 0 {
+		t.Errorf("expected empty checkpoint, got %v", cp.Completed)
+	}
+
+	cp.add("2025-02-01")
+	cp.add("2025-02-02")
+	cp.add("2025-02-01") // duplicate ignored
+	if err := cp.save(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := loadCheckpoint(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Completed) != 2 {
+		t.Fatalf("expected 2 completed dates, got %v", loaded.Completed)
+	}
+	if !loaded.has("2025-02-01") || !loaded.has("2025-02-02") || loaded.has("2025-02-03") {
+		t.Errorf("unexpected completed dates: %v", loaded.Completed)
+	}
+
+	// Corrupt file is an error
+	os.WriteFile(filename, []byte("not json"), 0644)
+	if _, err := loadCheckpoint(filename); err == nil {
+		t.Error("expected error for corrupt checkpoint")
+	}
+}
+
+func TestAppendAndReadRawCSV(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "power.csv")
+
+	day1 := growatt.PowerData{Date: "2025-02-01", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 4000}}}
+	day2 := growatt.PowerData{Date: "2025-02-02", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 4500}, {Time: "12:05", Power: 4510.5}}}
+
+	if err := appendRawCSV(filename, []growatt.PowerData{day1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := appendRawCSV(filename, []growatt.PowerData{day2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := readRawCSV(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(data))
+	}
+	if data[0].Date != "2025-02-01" || len(data[0].Powers) != 1 {
+		t.Errorf("unexpected first day: %+v", data[0])
+	}
+	if data[1].Date != "2025-02-02" || len(data[1].Powers) != 2 || data[1].Powers[1].Power != 4510.5 {
+		t.Errorf("unexpected second day: %+v", data[1])
+	}
+}
+
+func TestFetchWithCheckpointResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	rawFile := filepath.Join(tmpDir, "power.csv")
+	checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		requested = append(requested, day)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "datas": [{"time": "` + day + ` 12:00:00", "pac": 4000}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token",
+		growatt.WithBaseURL(server.URL+"/"),
+		growatt.WithRateLimit(0),
+	)
+
+	// Simulate an earlier interrupted run that completed the first day
+	if err := appendRawCSV(rawFile, []growatt.PowerData{
+		{Date: "2025-02-01", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 3000}}},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp := &checkpoint{Completed: []string{"2025-02-01"}}
+	if err := cp.save(checkpointPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	quiet = true
+	defer func() { quiet = false }()
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	// Each day is handed on as it becomes available, fetched or not
+	var streamed []string
+	onDay := func(pd *growatt.PowerData) {
+		streamed = append(streamed, fmt.Sprintf("%s@%d", pd.Date, len(requested)))
+	}
+
+	data, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, onDay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"2025-02-01@0", "2025-02-02@1", "2025-02-03@2"}; !reflect.DeepEqual(streamed, want) {
+		t.Errorf("expected days streamed as fetched %v, got %v", want, streamed)
+	}
+
+	// Only the remaining days are fetched
+	if len(requested) != 2 || requested[0] != "2025-02-02" || requested[1] != "2025-02-03" {
+		t.Errorf("expected requests for 2025-02-02 and 2025-02-03, got %v", requested)
+	}
+
+	// All three days are returned, including the previously exported one
+	if len(data) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(data))
+	}
+	if data[0].Powers[0].Power != 3000 {
+		t.Errorf("expected previously exported data to be preserved, got %+v", data[0])
+	}
+
+	loaded, err := loadCheckpoint(checkpointPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Completed) != 3 {
+		t.Errorf("expected 3 completed dates, got %v", loaded.Completed)
+	}
+
+	// Rerunning a completed export makes no requests
+	requested = nil
+	if _, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requested) != 0 {
+		t.Errorf("expected no requests on completed rerun, got %v", requested)
+	}
+}
+
+func TestFetchWithCheckpoint_DiscardsUncheckpointedRows(t *testing.T) {
+	tmpDir := t.TempDir()
+	rawFile := filepath.Join(tmpDir, "power.csv")
+	checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		w.Header().Set("Content-Type", "application/json")
+		if day == "2025-02-03" {
+			w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "datas": []}}`))
+			return
+		}
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "datas": [{"time": "` + day + ` 12:00:00", "pac": 4000}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token",
+		growatt.WithBaseURL(server.URL+"/"),
+		growatt.WithRateLimit(0),
+	)
+
+	// The first day was checkpointed; the run then crashed after appending
+	// the second day's rows but before recording it
+	if err := appendRawCSV(rawFile, []growatt.PowerData{
+		{Date: "2025-02-01", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 3000}}},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	size, err := fileSize(rawFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp := &checkpoint{Completed: []string{"2025-02-01"}, RawCSVSize: &size}
+	if err := cp.save(checkpointPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := appendRawCSV(rawFile, []growatt.PowerData{
+		{Date: "2025-02-02", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 4000}}},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	quiet = true
+	defer func() { quiet = false }()
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	data, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data) != 3 {
+		t.Fatalf("expected 3 days including the empty one, got %d: %+v", len(data), data)
+	}
+	if len(data[1].Powers) != 1 {
+		t.Errorf("expected the resumed day once, got %d points", len(data[1].Powers))
+	}
+	if data[2].Date != "2025-02-03" || len(data[2].Powers) != 0 {
+		t.Errorf("expected an empty last day, got %+v", data[2])
+	}
+}
+
+func TestFetchWithCheckpoint_RawCSVMismatch(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		requested = append(requested, day)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "datas": [{"time": "` + day + ` 12:00:00", "pac": 4000}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token",
+		growatt.WithBaseURL(server.URL+"/"),
+		growatt.WithRateLimit(0),
+	)
+
+	quiet = true
+	defer func() { quiet = false }()
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+	day1 := []growatt.PowerData{{Date: "2025-02-01", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 3000}}}}
+
+	t.Run("existing CSV without checkpoint", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		rawFile := filepath.Join(tmpDir, "power.csv")
+		if err := appendRawCSV(rawFile, day1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		requested = nil
+		_, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, filepath.Join(tmpDir, "checkpoint.json"), nil)
+		if err == nil {
+			t.Fatal("expected an error for a raw CSV the checkpoint did not write")
+		}
+		if len(requested) != 0 {
+			t.Errorf("expected no requests, got %v", requested)
+		}
+	})
+
+	t.Run("CSV smaller than recorded", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		rawFile := filepath.Join(tmpDir, "power.csv")
+		checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+		if _, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		os.Remove(rawFile)
+		requested = nil
+		if _, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, nil); err == nil {
+			t.Fatal("expected an error when the raw CSV lost checkpointed rows")
+		}
+		if len(requested) != 0 {
+			t.Errorf("expected no requests, got %v", requested)
+		}
+	})
+
+	t.Run("completed day missing from CSV is refetched", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		rawFile := filepath.Join(tmpDir, "power.csv")
+		checkpointPath := filepath.Join(tmpDir, "checkpoint.json")
+		if err := appendRawCSV(rawFile, day1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// A checkpoint from before sizes and empty days were recorded
+		cp := &checkpoint{Completed: []string{"2025-02-01", "2025-02-02"}}
+		if err := cp.save(checkpointPath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		requested = nil
+		data, err := fetchWithCheckpoint(context.Background(), client, "ABC123456", from, to, "UTC", rawFile, checkpointPath, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(requested) != 1 || requested[0] != "2025-02-02" {
+			t.Errorf("expected only 2025-02-02 to be refetched, got %v", requested)
+		}
+		if len(data) != 2 || len(data[1].Powers) != 1 {
+			t.Errorf("expected the refetched day's readings, got %+v", data)
+		}
+	})
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gogrowatt/internal/config"
 	"github.com/gogrowatt/internal/stats"
 	"github.com/gogrowatt/pkg/growatt"
 	"github.com/spf13/cobra"
@@ -21,6 +26,17 @@ const (
 	EnvTimezone = "GROWATT_TIMEZONE"
 )
 
+// maxRangeDays is the largest range exported without --yes
+const maxRangeDays = 370
+
+// dateLayouts are the accepted date formats for --date/--from/--to, ISO first
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"20060102",
+}
+
 var (
 	plantID   string
 	deviceSN  string
@@ -32,6 +48,27 @@ var (
 	token     string
 	baseURL   string
 	showGraph bool
+	graphFile string
+	dateFmt   string
+	statsFmt  string
+	quiet     bool
+	energy    bool
+	yes       bool
+
+	checkpointFile string
+	fields         string
+	mpptStrings    bool
+	graphMode      string
+	utcTimes       bool
+	reportedEnergy bool
+	fallbackYest   bool
+	precision      = -1 // -1 keeps each column's default
+	peakPower      float64
+	peakMetrics    bool
+	energyFmt      string
+	combine        bool
+	minSamples     int
+	configFile     string
 )
 
 func main() {
@@ -43,8 +80,9 @@ func main() {
 Outputs:
   - Raw CSV with 5-minute intervals
   - Hourly aggregated CSV
-  - Multi-day statistics markdown (when date range spans multiple days)
+  - Multi-day statistics markdown or JSON (when date range spans multiple days)
   - ASCII graph of hourly production (with --graph flag)
+  - SVG chart of hourly production (with --graph-file flag)
 
 For MIN/TLX inverters, use --device-sn or set GROWATT_DEVICE_SN for accurate data.
 If you have only one plant/device, IDs will be auto-detected.
@@ -54,21 +92,44 @@ Examples:
   growatt-export --graph today
   growatt-export --plant-id=12345 today
   growatt-export --date=2025-02-01
-  growatt-export --from=2025-01-01 --to=2025-01-31 -g`,
+  growatt-export --from=2025-01-01 --to=2025-01-31 -g
+  growatt-export --energy --from=2024-01-01 --to=2024-12-31
+  growatt-export --device-sn=SN1,SN2,SN3 --combine today
+  growatt-export --energy --format=heatmap --from=2024-01-01 --to=2024-12-31 -y`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: run,
 	}
 
+	rootCmd.Flags().StringVar(&configFile, "config", "", "Config file supplying defaults for --plant-id, --device-sn, --timezone and --base-url")
 	rootCmd.Flags().StringVar(&plantID, "plant-id", "", "Plant ID (auto-detected if only one plant, or set GROWATT_PLANT_ID)")
-	rootCmd.Flags().StringVar(&deviceSN, "device-sn", "", "Device serial number for MIN/TLX inverters (or set GROWATT_DEVICE_SN)")
+	rootCmd.Flags().StringVar(&deviceSN, "device-sn", "", "Device serial number for MIN/TLX inverters, or a comma-separated list to export several (or set GROWATT_DEVICE_SN)")
+	rootCmd.Flags().BoolVar(&combine, "combine", false, "With several --device-sn values, also write the summed power of all devices")
 	rootCmd.Flags().StringVar(&timezone, "timezone", "", "Timezone for device queries (default: US/Central, or set GROWATT_TIMEZONE)")
 	rootCmd.Flags().StringVar(&fromDate, "from", "", "Start date (YYYY-MM-DD)")
 	rootCmd.Flags().StringVar(&toDate, "to", "", "End date (YYYY-MM-DD)")
 	rootCmd.Flags().StringVar(&date, "date", "", "Single date (YYYY-MM-DD)")
+	rootCmd.Flags().StringVar(&dateFmt, "date-format", "", "Go layout for date flags (e.g. 02/01/2006), overrides the built-in formats")
 	rootCmd.Flags().StringVarP(&folder, "folder", "f", "./data", "Output folder for CSV files")
 	rootCmd.Flags().StringVar(&token, "token", "", "API token (overrides GROWATT_API_KEY)")
 	rootCmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL")
+	rootCmd.Flags().BoolVar(&energy, "energy", false, "Export daily energy (kWh) from the plant energy endpoint instead of 5-minute power")
+	rootCmd.Flags().StringVar(&energyFmt, "format", "csv", "Energy export format with --energy: csv (one row per day) or heatmap (weekday by week grid)")
+	rootCmd.Flags().BoolVarP(&yes, "yes", "y", false, fmt.Sprintf("Allow date ranges longer than %d days", maxRangeDays))
+	rootCmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "Checkpoint file recording completed days; rerunning resumes and appends to existing CSVs")
+	rootCmd.Flags().StringVar(&fields, "fields", "", "Comma-separated MIN telemetry columns for the raw CSV (e.g. time,pac,ppv,temperature)")
+	rootCmd.Flags().BoolVar(&mpptStrings, "strings", false, "Also write per-string (MPPT) hourly power to strings_<date>.csv")
+	rootCmd.Flags().BoolVar(&utcTimes, "utc", false, "Write raw CSV rows as RFC3339 UTC timestamps instead of local date and time")
+	rootCmd.Flags().BoolVar(&reportedEnergy, "reported-energy", false, "Use the plant's reported daily energy for statistics totals, integrating power only for days without it")
+	rootCmd.Flags().BoolVar(&fallbackYest, "fallback-yesterday", false, "If today has no data yet, export yesterday instead")
+	rootCmd.Flags().IntVar(&precision, "precision", -1, "Decimal places for numbers in CSV and JSON output (default: 2 for power, 3 for hourly energy)")
+	rootCmd.Flags().IntVar(&minSamples, "min-samples", 0, "Leave hours with fewer readings than this out of averages and energy (default: count every hour with readings)")
+	rootCmd.Flags().Float64Var(&peakPower, "peak-power", 0, "Plant peak power in kW for sun hours, specific yield and capacity factor in statistics")
+	rootCmd.Flags().BoolVar(&peakMetrics, "peak-metrics", false, "Include sun hours, specific yield and capacity factor in statistics, reading the peak power from the plant details unless --peak-power is set")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress per-day progress output")
 	rootCmd.Flags().BoolVarP(&showGraph, "graph", "g", false, "Display ASCII graph of hourly power production")
+	rootCmd.Flags().StringVar(&statsFmt, "stats-format", "md", "Multi-day statistics format (md or json)")
+	rootCmd.Flags().StringVar(&graphMode, "graph-mode", "hour", "ASCII graph mode: hour (averaged hourly power) or day (energy per day)")
+	rootCmd.Flags().StringVar(&graphFile, "graph-file", "", "Render hourly power production chart to an SVG file")
 
 	// Don't show usage on errors during execution (only on bad CLI args)
 	rootCmd.SilenceUsage = true
@@ -79,34 +140,79 @@ Examples:
 }
 
 func run(cmd *cobra.Command, args []string) error {
+	if configFile != "" {
+		if err := applyConfig(configFile); err != nil {
+			return err
+		}
+	}
+
+	// Resolve timezone first so "today" is computed in the plant's zone
+	tz := resolveTimezone(timezone)
+
 	// Determine date range
-	var from, to time.Time
-	var err error
+	from, to, err := resolveDateRange(args, time.Now(), tz)
+	if err != nil {
+		return err
+	}
 
-	if len(args) > 0 && args[0] == "today" {
-		from = time.Now()
-		to = from
-	} else if date != "" {
-		from, err = time.Parse("2006-01-02", date)
-		if err != nil {
-			return fmt.Errorf("invalid date format: %w", err)
-		}
-		to = from
-	} else if fromDate != "" && toDate != "" {
-		from, err = time.Parse("2006-01-02", fromDate)
-		if err != nil {
-			return fmt.Errorf("invalid from date format: %w", err)
-		}
-		to, err = time.Parse("2006-01-02", toDate)
+	warning, err := checkRangeLimit(from, to, energy, yes)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	if statsFmt != "md" && statsFmt != "json" {
+		return fmt.Errorf("invalid stats format %q: must be md or json", statsFmt)
+	}
+
+	if energyFmt != "csv" && energyFmt != "heatmap" {
+		return fmt.Errorf("invalid format %q: must be csv or heatmap", energyFmt)
+	}
+
+	if energyFmt != "csv" && !energy {
+		return fmt.Errorf("--format requires --energy")
+	}
+
+	if minSamples < 0 {
+		return fmt.Errorf("invalid min samples %d: must be 0 or more", minSamples)
+	}
+
+	if precision < -1 {
+		return fmt.Errorf("invalid precision %d: must be 0 or more", precision)
+	}
+
+	if graphMode != "hour" && graphMode != "day" {
+		return fmt.Errorf("invalid graph mode %q: must be hour or day", graphMode)
+	}
+
+	if checkpointFile != "" && (fields != "" || mpptStrings) {
+		return fmt.Errorf("--fields and --strings cannot be combined with --checkpoint")
+	}
+
+	if fallbackYest && checkpointFile != "" {
+		return fmt.Errorf("--fallback-yesterday cannot be combined with --checkpoint")
+	}
+
+	if utcTimes && (checkpointFile != "" || fields != "") {
+		return fmt.Errorf("--utc cannot be combined with --checkpoint or --fields")
+	}
+
+	var loc *time.Location
+	if utcTimes {
+		loc, err = growatt.ResolveTimezone(tz)
 		if err != nil {
-			return fmt.Errorf("invalid to date format: %w", err)
+			return fmt.Errorf("invalid timezone: %w", err)
 		}
-	} else {
-		return fmt.Errorf("must specify 'today', --date, or --from/--to")
 	}
 
-	if to.Before(from) {
-		return fmt.Errorf("end date cannot be before start date")
+	var columns []string
+	if fields != "" {
+		columns, err = parseFields(fields)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Create client
@@ -114,6 +220,10 @@ func run(cmd *cobra.Command, args []string) error {
 	if baseURL != "" {
 		opts = append(opts, growatt.WithBaseURL(baseURL))
 	}
+	if !quiet {
+		opts = append(opts, growatt.WithProgress(printProgress))
+	}
+	opts = append(opts, growatt.WithWarnings(printWarning))
 
 	var client *growatt.Client
 	if token != "" {
@@ -127,19 +237,35 @@ func run(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 
+	if energy {
+		return runEnergy(ctx, client, from, to)
+	}
+
 	// Resolve device serial number (preferred for MIN/TLX inverters)
-	resolvedDeviceSN, err := resolveDeviceSN(ctx, client, deviceSN, plantID)
+	resolvedDeviceSN, resolvedPlantID, err := resolveDeviceSN(ctx, client, deviceSN, plantID)
 	if err != nil {
 		return err
 	}
 
-	// Resolve timezone
-	tz := timezone
-	if tz == "" {
-		tz = os.Getenv(EnvTimezone)
+	// Several serials are exported to per-device files
+	serials := parseDeviceSNs(resolvedDeviceSN)
+	if len(serials) > 1 {
+		if checkpointFile != "" || fields != "" || mpptStrings || fallbackYest {
+			return fmt.Errorf("--checkpoint, --fields, --strings and --fallback-yesterday cannot be combined with several device serials")
+		}
+		return runMultiDevice(ctx, client, serials, from, to, tz, loc)
 	}
-	if tz == "" {
-		tz = "US/Central"
+	if len(serials) == 1 {
+		resolvedDeviceSN = serials[0]
+	}
+
+	// Multi-day statistics may need the plant; resolve it now rather than
+	// prompting partway through the export
+	if resolvedPlantID == "" && !from.Equal(to) && (reportedEnergy || (peakMetrics && peakPower <= 0)) {
+		resolvedPlantID, err = resolvePlantIDQuiet(ctx, client, plantID)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Ensure output folder exists
@@ -150,45 +276,84 @@ func run(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Fetching power data for device %s from %s to %s...\n",
 		resolvedDeviceSN, from.Format("2006-01-02"), to.Format("2006-01-02"))
 
-	// Fetch data using device-specific endpoint (works for MIN/TLX inverters)
-	powerData, err := client.GetMINInverterHistoryRange(ctx, resolvedDeviceSN, from, to, tz)
-	if err != nil {
-		return fmt.Errorf("fetching power data: %w", err)
-	}
+	// Days are parsed and aggregated in the background as they arrive, overlapping
+	// with the rate-limit wait before the next fetch
+	aggregator := startDayAggregator()
+	defer aggregator.wait()
+
+	var powerData []growatt.PowerData
+	var days []growatt.MINHistoryDay
+	if checkpointFile == "" {
+		// Only a single-day export of today falls back to yesterday
+		fallback := false
+		if fallbackYest && from.Equal(to) {
+			today, _, err := resolveDateRange([]string{"today"}, time.Now(), tz)
+			fallback = err == nil && from.Equal(today)
+		}
 
-	if len(powerData) == 0 {
-		return fmt.Errorf("no data returned")
+		var used time.Time
+		powerData, days, used, err = fetchPowerWithFallback(ctx, client, resolvedDeviceSN, from, to, tz, columns != nil || mpptStrings, fallback, aggregator.add)
+		if err != nil {
+			return fmt.Errorf("fetching power data: %w", err)
+		}
+		if !used.Equal(from) {
+			fmt.Printf("No data yet for %s; using yesterday (%s) instead\n",
+				from.Format("2006-01-02"), used.Format("2006-01-02"))
+			from, to = used, used
+		}
 	}
 
 	// Generate filenames
-	var rawCSVFile, hourlyCSVFile, statsFile string
+	var rawCSVFile, hourlyCSVFile, stringsCSVFile, statsFile string
 	if from.Equal(to) {
 		dateStr := from.Format("2006-01-02")
 		rawCSVFile = filepath.Join(folder, fmt.Sprintf("power_%s.csv", dateStr))
 		hourlyCSVFile = filepath.Join(folder, fmt.Sprintf("hourly_%s.csv", dateStr))
+		stringsCSVFile = filepath.Join(folder, fmt.Sprintf("strings_%s.csv", dateStr))
 	} else {
 		dateRange := fmt.Sprintf("%s_to_%s", from.Format("2006-01-02"), to.Format("2006-01-02"))
 		rawCSVFile = filepath.Join(folder, fmt.Sprintf("power_%s.csv", dateRange))
 		hourlyCSVFile = filepath.Join(folder, fmt.Sprintf("hourly_%s.csv", dateRange))
-		statsFile = filepath.Join(folder, fmt.Sprintf("stats_%s.md", dateRange))
+		stringsCSVFile = filepath.Join(folder, fmt.Sprintf("strings_%s.csv", dateRange))
+		statsFile = filepath.Join(folder, fmt.Sprintf("stats_%s.%s", dateRange, statsFmt))
+	}
+
+	if checkpointFile != "" {
+		// Fetch day by day, appending to the raw CSV and skipping completed days
+		powerData, err = fetchWithCheckpoint(ctx, client, resolvedDeviceSN, from, to, tz, rawCSVFile, checkpointFile, aggregator.add)
+		if err != nil {
+			return fmt.Errorf("fetching power data: %w", err)
+		}
+	} else if len(powerData) > 0 {
+		switch {
+		case columns != nil:
+			err = writeFieldsCSV(rawCSVFile, days, columns)
+		case utcTimes:
+			err = writeUTCRawCSV(rawCSVFile, powerData, loc)
+		default:
+			err = writeRawCSV(rawCSVFile, powerData)
+		}
+		if err != nil {
+			return fmt.Errorf("writing raw CSV: %w", err)
+		}
 	}
 
-	// Write raw CSV
-	if err := writeRawCSV(rawCSVFile, powerData); err != nil {
-		return fmt.Errorf("writing raw CSV: %w", err)
+	if len(powerData) == 0 {
+		return fmt.Errorf("no data returned")
 	}
 	fmt.Printf("Wrote raw data to %s\n", rawCSVFile)
 
-	// Parse and aggregate to hourly
-	var dailyStats []*stats.DailyStats
-	for _, pd := range powerData {
-		parsed, err := growatt.ParsePowerData(&pd)
-		if err != nil {
-			return fmt.Errorf("parsing power data: %w", err)
-		}
-		if ds := stats.AggregateToHourly(parsed); ds != nil {
-			dailyStats = append(dailyStats, ds)
+	if mpptStrings {
+		if err := writeStringsCSV(stringsCSVFile, days); err != nil {
+			return fmt.Errorf("writing strings CSV: %w", err)
 		}
+		fmt.Printf("Wrote per-string hourly data to %s\n", stringsCSVFile)
+	}
+
+	// Collect the hourly aggregates
+	dailyStats, err := aggregator.wait()
+	if err != nil {
+		return err
 	}
 
 	// Write hourly CSV
@@ -200,13 +365,40 @@ func run(cmd *cobra.Command, args []string) error {
 	// Display ASCII graph if requested
 	if showGraph && len(dailyStats) > 0 {
 		fmt.Println()
-		printASCIIGraph(dailyStats)
+		if graphMode == "day" {
+			printDailyEnergyGraph(dailyStats)
+		} else {
+			printASCIIGraph(dailyStats)
+		}
+	}
+
+	// Render graph file if requested
+	if graphFile != "" && len(dailyStats) > 0 {
+		if err := writeGraphFile(graphFile, dailyStats); err != nil {
+			return fmt.Errorf("writing graph file: %w", err)
+		}
+		fmt.Printf("Wrote graph to %s\n", graphFile)
 	}
 
 	// Write multi-day stats if applicable
 	if len(dailyStats) > 1 && statsFile != "" {
 		multiDay := stats.AggregateDays(dailyStats)
-		if err := writeStatsMarkdown(statsFile, multiDay); err != nil {
+		if reportedEnergy {
+			if reported := lookupReportedEnergy(ctx, client, resolvedPlantID, from, to); reported != nil {
+				used := multiDay.UseReportedEnergy(dailyStats, reported)
+				fmt.Printf("Using reported energy for %d of %d days\n", used, len(dailyStats))
+			}
+		}
+		// Looked up once and shared by every output that needs it
+		peakKW := peakPower
+		if peakKW <= 0 && peakMetrics {
+			peakKW = lookupPeakPower(ctx, client, resolvedPlantID, 0)
+		}
+		if statsFmt == "json" {
+			if err := writeStatsJSON(statsFile, multiDay, dailyStats, peakKW); err != nil {
+				return fmt.Errorf("writing stats JSON: %w", err)
+			}
+		} else if err := writeStatsMarkdown(statsFile, multiDay, dailyStats, peakKW); err != nil {
 			return fmt.Errorf("writing stats markdown: %w", err)
 		}
 		fmt.Printf("Wrote statistics to %s\n", statsFile)
@@ -215,32 +407,249 @@ func run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// resolveDeviceSN determines the device serial number to use
-func resolveDeviceSN(ctx context.Context, client *growatt.Client, deviceFlag, plantFlag string) (string, error) {
+// fetchPower fetches power for the range. When detailed is set the full MIN
+// telemetry is fetched as well, for selected columns or per-string power.
+// If onDay is set it receives each day as soon as it is fetched.
+func fetchPower(ctx context.Context, client *growatt.Client, serial string, from, to time.Time, tz string, detailed bool, onDay func(*growatt.PowerData)) ([]growatt.PowerData, []growatt.MINHistoryDay, error) {
+	var powerData []growatt.PowerData
+	var days []growatt.MINHistoryDay
+
+	// Device-specific endpoint (works for MIN/TLX inverters)
+	err := client.EachMINInverterHistoryDay(ctx, serial, from, to, tz, func(day *growatt.MINHistoryDay) error {
+		pd := day.PowerData()
+		powerData = append(powerData, *pd)
+		if detailed {
+			days = append(days, *day)
+		}
+		if onDay != nil {
+			onDay(pd)
+		}
+		return nil
+	})
+	if err != nil && detailed {
+		return nil, nil, err
+	}
+	return powerData, days, err
+}
+
+// fetchPowerWithFallback fetches power for the range and, if fallback is set
+// and the range has no readings, fetches the day before from instead. It
+// returns the start date actually exported.
+func fetchPowerWithFallback(ctx context.Context, client *growatt.Client, serial string, from, to time.Time, tz string, detailed, fallback bool, onDay func(*growatt.PowerData)) ([]growatt.PowerData, []growatt.MINHistoryDay, time.Time, error) {
+	powerData, days, err := fetchPower(ctx, client, serial, from, to, tz, detailed, onDay)
+	if err != nil || !fallback || hasReadings(powerData) {
+		return powerData, days, from, err
+	}
+
+	yesterday := from.AddDate(0, 0, -1)
+	powerData, days, err = fetchPower(ctx, client, serial, yesterday, yesterday, tz, detailed, onDay)
+	return powerData, days, yesterday, err
+}
+
+// hasReadings reports whether any day contains at least one power reading
+func hasReadings(data []growatt.PowerData) bool {
+	for _, day := range data {
+		if len(day.Powers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runEnergy exports the daily energy series for the date range
+func runEnergy(ctx context.Context, client *growatt.Client, from, to time.Time) error {
+	resolvedPlantID, err := resolvePlantID(ctx, client, plantID)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return fmt.Errorf("creating output folder: %w", err)
+	}
+
+	fmt.Printf("Fetching daily energy for plant %s from %s to %s...\n",
+		resolvedPlantID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	energyData, err := client.GetPlantEnergyRange(ctx, resolvedPlantID, from, to)
+	if err != nil {
+		return fmt.Errorf("fetching energy data: %w", err)
+	}
+
+	prefix := "energy"
+	if energyFmt == "heatmap" {
+		prefix = "heatmap"
+	}
+
+	var energyCSVFile string
+	if from.Equal(to) {
+		energyCSVFile = filepath.Join(folder, fmt.Sprintf("%s_%s.csv", prefix, from.Format("2006-01-02")))
+	} else {
+		dateRange := fmt.Sprintf("%s_to_%s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+		energyCSVFile = filepath.Join(folder, fmt.Sprintf("%s_%s.csv", prefix, dateRange))
+	}
+
+	if energyFmt == "heatmap" {
+		if err := writeHeatmapCSV(energyCSVFile, energyData, from, to); err != nil {
+			return fmt.Errorf("writing heatmap CSV: %w", err)
+		}
+		fmt.Printf("Wrote energy heatmap to %s\n", energyCSVFile)
+		return nil
+	}
+
+	if err := writeEnergyCSV(energyCSVFile, energyData); err != nil {
+		return fmt.Errorf("writing energy CSV: %w", err)
+	}
+	fmt.Printf("Wrote energy data to %s\n", energyCSVFile)
+
+	return nil
+}
+
+// applyConfig fills settings not given on the command line from a config
+// file. Precedence is CLI flag > config file > environment variable > default.
+func applyConfig(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	config.SetDefault(&plantID, cfg.PlantID)
+	config.SetDefault(&deviceSN, cfg.DeviceSN)
+	config.SetDefault(&timezone, cfg.Timezone)
+	config.SetDefault(&baseURL, cfg.BaseURL)
+	return nil
+}
+
+// resolveTimezone determines the timezone to use for device queries
+func resolveTimezone(flagValue string) string {
+	// Priority: CLI flag (or config file) > environment variable > default
+	if flagValue != "" {
+		return flagValue
+	}
+	if envValue := os.Getenv(EnvTimezone); envValue != "" {
+		return envValue
+	}
+	return "US/Central"
+}
+
+// resolveDateRange determines the from/to dates from args and flags.
+// "today" is evaluated in the given timezone rather than the machine's local zone.
+func resolveDateRange(args []string, now time.Time, tz string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+
+	layouts := dateLayouts
+	if dateFmt != "" {
+		layouts = []string{dateFmt}
+	}
+
+	if len(args) > 0 && args[0] == "today" {
+		loc, err := growatt.ResolveTimezone(tz)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid timezone: %w", err)
+		}
+		local := now.In(loc)
+		from = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		to = from
+	} else if date != "" {
+		from, err = parseDate(date, layouts)
+		if err != nil {
+			return from, to, err
+		}
+		to = from
+	} else if fromDate != "" && toDate != "" {
+		from, err = parseDate(fromDate, layouts)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date: %w", err)
+		}
+		to, err = parseDate(toDate, layouts)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date: %w", err)
+		}
+	} else {
+		return from, to, fmt.Errorf("must specify 'today', --date, or --from/--to")
+	}
+
+	if to.Before(from) {
+		return from, to, fmt.Errorf("end date cannot be before start date")
+	}
+
+	return from, to, nil
+}
+
+// printProgress prints a per-day progress line during range fetches
+func printProgress(done, total int, date time.Time) {
+	fmt.Printf("[%d/%d] %s\n", done, total, date.Format("2006-01-02"))
+}
+
+// rangeDays returns the number of days in the inclusive range
+func rangeDays(from, to time.Time) int {
+	days := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// estimateRequests returns the approximate number of API calls needed for the range
+func estimateRequests(days int, energyMode bool) int {
+	if energyMode {
+		return (days + growatt.MaxEnergyDayRange - 1) / growatt.MaxEnergyDayRange
+	}
+	return days
+}
+
+// checkRangeLimit rejects ranges longer than maxRangeDays unless confirmed,
+// returning a warning to show when a long range is confirmed
+func checkRangeLimit(from, to time.Time, energyMode, confirmed bool) (string, error) {
+	days := rangeDays(from, to)
+	if days <= maxRangeDays {
+		return "", nil
+	}
+
+	requests := estimateRequests(days, energyMode)
+	if !confirmed {
+		return "", fmt.Errorf("date range of %d days (~%d API requests) exceeds %d days; pass --yes to proceed", days, requests, maxRangeDays)
+	}
+	return fmt.Sprintf("Warning: exporting %d days will take ~%d API requests", days, requests), nil
+}
+
+// parseDate parses a date string using the first matching layout
+func parseDate(value string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: accepted formats are %s", value, strings.Join(layouts, ", "))
+}
+
+// resolveDeviceSN determines the device serial number to use. The plant ID is
+// returned too when auto-detection had to resolve it, and is empty otherwise.
+func resolveDeviceSN(ctx context.Context, client *growatt.Client, deviceFlag, plantFlag string) (string, string, error) {
 	// Priority: CLI flag > environment variable > auto-detect
 	if deviceFlag != "" {
-		return deviceFlag, nil
+		return deviceFlag, "", nil
 	}
 
 	if envValue := os.Getenv(EnvDeviceSN); envValue != "" {
 		fmt.Printf("Using device SN from %s: %s\n", EnvDeviceSN, envValue)
-		return envValue, nil
+		return envValue, "", nil
 	}
 
 	// Need to auto-detect: first get plant ID, then get device list
 	plantID, err := resolvePlantIDQuiet(ctx, client, plantFlag)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	fmt.Println("Fetching device list...")
 	devices, err := client.ListDevices(ctx, plantID)
 	if err != nil {
-		return "", fmt.Errorf("failed to list devices: %w", err)
+		return "", "", fmt.Errorf("failed to list devices: %w", err)
 	}
 
 	if len(devices) == 0 {
-		return "", fmt.Errorf("no devices found for plant %s", plantID)
+		return "", "", fmt.Errorf("no devices found for plant %s", plantID)
 	}
 
 	if len(devices) == 1 {
@@ -251,7 +660,7 @@ func resolveDeviceSN(ctx context.Context, client *growatt.Client, deviceFlag, pl
 		fmt.Printf("  export %s=%s\n", EnvPlantID, plantID)
 		fmt.Printf("  export %s=%s\n", EnvDeviceSN, sn)
 		fmt.Println()
-		return sn, nil
+		return sn, plantID, nil
 	}
 
 	// Multiple devices - user must specify
@@ -262,7 +671,7 @@ func resolveDeviceSN(ctx context.Context, client *growatt.Client, deviceFlag, pl
 	fmt.Println()
 	fmt.Println("Set one of these as your default:")
 	fmt.Printf("  export %s=<device-sn>\n", EnvDeviceSN)
-	return "", fmt.Errorf("multiple devices found; specify --device-sn or set %s environment variable", EnvDeviceSN)
+	return "", "", fmt.Errorf("multiple devices found; specify --device-sn or set %s environment variable", EnvDeviceSN)
 }
 
 // resolvePlantID determines the plant ID to use (with tips shown)
@@ -321,6 +730,48 @@ func resolvePlantIDInternal(ctx context.Context, client *growatt.Client, flagVal
 	return "", fmt.Errorf("multiple plants found; specify --plant-id or set %s environment variable", EnvPlantID)
 }
 
+// lookupPeakPower returns the plant's peak power in kW: the --peak-power
+// override if set, otherwise the value from the plant details, or 0 with a
+// warning if the details are unavailable
+func lookupPeakPower(ctx context.Context, client *growatt.Client, plantID string, override float64) float64 {
+	if override > 0 {
+		return override
+	}
+
+	details, err := client.GetPlantDetails(ctx, plantID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: peak power unavailable, leaving out sun hours, specific yield and capacity factor: %v\n", err)
+		return 0
+	}
+	return details.PeakPowerKW()
+}
+
+// lookupReportedEnergy returns the plant's reported daily energy (kWh) keyed
+// by date, or nil with a warning if it cannot be fetched
+func lookupReportedEnergy(ctx context.Context, client *growatt.Client, plantID string, from, to time.Time) map[string]float64 {
+	energyData, err := client.GetPlantEnergyRange(ctx, plantID, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: reported energy unavailable, integrating power instead: %v\n", err)
+		return nil
+	}
+
+	return reportedEnergyByDate(energyData)
+}
+
+// reportedEnergyByDate indexes daily energy by date
+func reportedEnergyByDate(data *growatt.EnergyData) map[string]float64 {
+	reported := make(map[string]float64, len(data.Datas))
+	for _, d := range data.Datas {
+		reported[d.Date] = d.Energy
+	}
+	return reported
+}
+
+// printWarning reports a problem the client worked around on stderr
+func printWarning(msg string) {
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+}
+
 func writeRawCSV(filename string, data []growatt.PowerData) error {
 	f, err := os.Create(filename)
 	if err != nil {
@@ -336,13 +787,17 @@ func writeRawCSV(filename string, data []growatt.PowerData) error {
 		return err
 	}
 
-	// Data
+	return writeRawRows(w, data)
+}
+
+// writeRawRows writes one CSV row per power reading
+func writeRawRows(w *csv.Writer, data []growatt.PowerData) error {
 	for _, day := range data {
 		for _, p := range day.Powers {
 			if err := w.Write([]string{
 				day.Date,
 				p.Time,
-				strconv.FormatFloat(p.Power, 'f', 2, 64),
+				formatFloat(p.Power, 2),
 			}); err != nil {
 				return err
 			}
@@ -352,7 +807,9 @@ func writeRawCSV(filename string, data []growatt.PowerData) error {
 	return nil
 }
 
-func writeHourlyCSV(filename string, data []*stats.DailyStats) error {
+// writeUTCRawCSV writes one row per power reading with the reading's local
+// time in loc converted to an RFC3339 UTC timestamp
+func writeUTCRawCSV(filename string, data []growatt.PowerData, loc *time.Location) error {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -362,25 +819,264 @@ func writeHourlyCSV(filename string, data []*stats.DailyStats) error {
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
+	if err := w.Write([]string{"timestamp", "power_watts"}); err != nil {
+		return err
+	}
+
+	for _, day := range data {
+		for _, p := range day.Powers {
+			ts, err := utcTimestamp(day.Date, p.Time, loc)
+			if err != nil {
+				return err
+			}
+			if err := w.Write([]string{
+				ts,
+				formatFloat(p.Power, 2),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// utcTimestamp converts a local date and HH:MM clock time in loc to RFC3339 UTC.
+// time.Date resolves DST gaps and carries an end-of-day "24:00" into the next day.
+func utcTimestamp(date, clock string, loc *time.Location) (string, error) {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", fmt.Errorf("parsing date %s: %w", date, err)
+	}
+
+	// Accept full "YYYY-MM-DD HH:MM" values as well as bare clock times
+	if i := strings.LastIndex(clock, " "); i >= 0 {
+		clock = clock[i+1:]
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("parsing time %q: expected HH:MM", clock)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("parsing time %q: %w", clock, err)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("parsing time %q: %w", clock, err)
+	}
+
+	t := time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, loc)
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// parseFields validates a comma-separated column list for the raw CSV.
+// "date" and "time" are accepted alongside the MIN history field names.
+func parseFields(value string) ([]string, error) {
+	valid := map[string]bool{"date": true, "time": true}
+	for _, name := range growatt.MINHistoryFields() {
+		valid[name] = true
+	}
+
+	var columns []string
+	for _, f := range strings.Split(value, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if !valid[f] {
+			return nil, fmt.Errorf("unknown field %q: valid fields are date, time, %s",
+				f, strings.Join(growatt.MINHistoryFields(), ", "))
+		}
+		columns = append(columns, f)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no fields specified")
+	}
+	return columns, nil
+}
+
+// writeFieldsCSV writes the selected MIN telemetry columns, in order, one row per reading
+func writeFieldsCSV(filename string, days []growatt.MINHistoryDay, columns []string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+
+	for _, day := range days {
+		for _, p := range day.Points {
+			row := make([]string, len(columns))
+			for i, col := range columns {
+				switch col {
+				case "date":
+					row[i] = day.Date
+				case "time":
+					row[i] = p.ClockTime()
+				default:
+					v, err := p.Field(col)
+					if err != nil {
+						return err
+					}
+					row[i] = formatFloat(v, 2)
+				}
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeStringsCSV writes the hourly mean power of each MPPT input per day
+func writeStringsCSV(filename string, days []growatt.MINHistoryDay) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write([]string{"date", "hour", "string1_watts", "string2_watts", "samples"}); err != nil {
+		return err
+	}
+
+	for _, day := range days {
+		for _, h := range stats.AggregateStringsHourly(day.Points) {
+			if err := w.Write([]string{
+				day.Date,
+				strconv.Itoa(h.Hour),
+				formatFloat(h.String1, 2),
+				formatFloat(h.String2, 2),
+				strconv.Itoa(h.Samples),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeEnergyCSV(filename string, data *growatt.EnergyData) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	// Header
+	if err := w.Write([]string{"date", "kwh"}); err != nil {
+		return err
+	}
+
+	// Data
+	for _, d := range data.Datas {
+		if err := w.Write([]string{
+			d.Date,
+			formatFloat(d.Energy, 2),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHeatmapCSV writes daily energy as a calendar grid: one row per weekday
+// (Sunday first) and one column per week, headed by the week's Sunday. Days
+// outside the range or without data are left empty.
+func writeHeatmapCSV(filename string, data *growatt.EnergyData, from, to time.Time) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	heatmap := stats.ProductionHeatmap(data)
+	weeks := stats.HeatmapWeeks(from, to)
+
 	// Header
-	if err := w.Write([]string{"date", "hour", "min_watts", "max_watts", "avg_watts", "samples"}); err != nil {
+	header := []string{"weekday"}
+	for _, week := range weeks {
+		header = append(header, week.Start.Format("2006-01-02"))
+	}
+	if err := w.Write(header); err != nil {
 		return err
 	}
 
 	// Data
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		row := []string{day.String()[:3]}
+		for _, week := range weeks {
+			kwh, ok := heatmap[week.Days[day]]
+			if week.Days[day] == "" || !ok {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, formatFloat(kwh, 2))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeHourlyCSV(filename string, data []*stats.DailyStats) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	// Header
+	if err := w.Write([]string{"date", "hour", "min_watts", "max_watts", "avg_watts", "samples", "energy_kwh", "coverage_pct"}); err != nil {
+		return err
+	}
+
+	// Data; hours without samples leave min/max/avg blank so they are not
+	// mistaken for a genuine 0 W reading
 	rows := stats.GetHourlyRows(data)
 	for _, row := range rows {
-		minStr := "0"
-		if row.Min > 0 {
-			minStr = strconv.FormatFloat(row.Min, 'f', 2, 64)
+		var minStr, maxStr, avgStr string
+		if row.Samples > 0 {
+			minStr = formatFloat(row.Min, 2)
+			maxStr = formatFloat(row.Max, 2)
+			avgStr = formatFloat(row.Avg, 2)
 		}
 		if err := w.Write([]string{
 			row.Date,
 			strconv.Itoa(row.Hour),
 			minStr,
-			strconv.FormatFloat(row.Max, 'f', 2, 64),
-			strconv.FormatFloat(row.Avg, 'f', 2, 64),
+			maxStr,
+			avgStr,
 			strconv.Itoa(row.Samples),
+			formatFloat(row.Energy, 3),
+			formatFloat(row.CoveragePct, 1),
 		}); err != nil {
 			return err
 		}
@@ -389,7 +1085,8 @@ func writeHourlyCSV(filename string, data []*stats.DailyStats) error {
 	return nil
 }
 
-func writeStatsMarkdown(filename string, data *stats.MultiDayStats) error {
+// writeStatsMarkdown writes the multi-day summary; sun hours are included when peakKW is known
+func writeStatsMarkdown(filename string, data *stats.MultiDayStats, days []*stats.DailyStats, peakKW float64) error {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -407,6 +1104,15 @@ func writeStatsMarkdown(filename string, data *stats.MultiDayStats) error {
 	fmt.Fprintf(f, "| Peak Hour (avg) | %02d:00 |\n", data.PeakHour)
 	fmt.Fprintf(f, "| Peak Power (avg) | %.1f W |\n", data.PeakPowerAvg)
 	fmt.Fprintf(f, "| Daily Average Production | %.2f kWh |\n", data.DailyAverage)
+	if peakKW > 0 {
+		fmt.Fprintf(f, "| Equivalent Sun Hours (daily avg) | %.2f h |\n", data.SunHours(peakKW))
+		fmt.Fprintf(f, "| Specific Yield | %.2f kWh/kWp |\n", data.SpecificYield(peakKW))
+		fmt.Fprintf(f, "| Capacity Factor | %.1f%% |\n", data.CapacityFactor(peakKW)*100)
+	}
+	if best, worst, bestKWh, worstKWh := stats.BestWorstDays(days); best != nil {
+		fmt.Fprintf(f, "| Best Day | %s (%.2f kWh) |\n", best.Date, bestKWh)
+		fmt.Fprintf(f, "| Worst Day | %s (%.2f kWh) |\n", worst.Date, worstKWh)
+	}
 	fmt.Fprintf(f, "| Total Production | %.2f kWh |\n\n", data.TotalProduction)
 
 	// Hourly Statistics Table
@@ -430,9 +1136,6 @@ func writeStatsMarkdown(filename string, data *stats.MultiDayStats) error {
 	fmt.Fprintf(f, "- **Std Dev**: Standard deviation of hourly averages (variability indicator)\n")
 	fmt.Fprintf(f, "- **Days**: Number of days with data at this hour\n\n")
 
-	fmt.Fprintf(f, "## Raw Hourly Averages by Day\n\n")
-	fmt.Fprintf(f, "For detailed analysis, the following shows the average power per hour for each day:\n\n")
-
 	// Find hours with data
 	activeHours := []int{}
 	for hour := 0; hour < 24; hour++ {
@@ -441,7 +1144,10 @@ func writeStatsMarkdown(filename string, data *stats.MultiDayStats) error {
 		}
 	}
 
-	if len(activeHours) > 0 {
+	if len(days) > 0 && len(activeHours) > 0 {
+		fmt.Fprintf(f, "## Raw Hourly Averages by Day\n\n")
+		fmt.Fprintf(f, "For detailed analysis, the following shows the average power per hour for each day:\n\n")
+
 		// Header row with hours
 		fmt.Fprintf(f, "| Day |")
 		for _, hour := range activeHours {
@@ -456,108 +1162,357 @@ func writeStatsMarkdown(filename string, data *stats.MultiDayStats) error {
 		}
 		fmt.Fprintf(f, "\n")
 
-		// Data rows (we need the original daily data for this, but we don't have it here)
-		// This section would need the original DailyStats to populate
-		fmt.Fprintf(f, "\n*Note: Individual daily data available in the hourly CSV file.*\n")
+		// Data rows; hours without samples that day are shown as -
+		for _, day := range days {
+			fmt.Fprintf(f, "| %s |", day.Date)
+			for _, hour := range activeHours {
+				if h := day.Hours[hour]; h != nil && h.Samples > 0 {
+					fmt.Fprintf(f, " %.1f |", h.Mean)
+				} else {
+					fmt.Fprintf(f, " - |")
+				}
+			}
+			fmt.Fprintf(f, "\n")
+		}
 	}
 
 	return nil
 }
 
+// hourlyKWhSeries returns the averaged hourly kWh series, its maximum and its total
+func hourlyKWhSeries(dailyStats []*stats.DailyStats) ([]float64, float64, float64) {
+	series := stats.HourlyKWhSeries(dailyStats)
+	hourlyKWh := series[:]
+
+	// Find max for scaling and total daily kWh
+	maxKWh := 0.0
+	totalKWh := 0.0
+	for _, kwh := range hourlyKWh {
+		if kwh > maxKWh {
+			maxKWh = kwh
+		}
+		totalKWh += kwh
+	}
+
+	return hourlyKWh, maxKWh, totalKWh
+}
+
+// graphTitle returns the chart title shared by the ASCII and SVG graphs
+func graphTitle(dailyStats []*stats.DailyStats, totalKWh float64) string {
+	if len(dailyStats) == 1 {
+		return fmt.Sprintf("Power Production - %s (Total: %.2f kWh)", dailyStats[0].Date, totalKWh)
+	}
+	return fmt.Sprintf("Power Production - %d days averaged (Daily avg: %.2f kWh)", len(dailyStats), totalKWh)
+}
+
+// statsJSON is the JSON output structure for multi-day statistics. It and the
+// types below carry a rounded subset of the stats types under the same keys.
+type statsJSON struct {
+	StartDate       string          `json:"start_date"`
+	EndDate         string          `json:"end_date"`
+	DaysAnalyzed    int             `json:"days_analyzed"`
+	DaysWithData    int             `json:"days_with_data"`
+	TotalProduction float64         `json:"total_production_kwh"`
+	DailyAverage    float64         `json:"daily_average_kwh"`
+	PeakHour        int             `json:"peak_hour"`
+	PeakPowerAvg    float64         `json:"peak_power_avg_watts"`
+	PeakPowerKW     float64         `json:"peak_power_kw,omitempty"`
+	SunHours        float64         `json:"sun_hours,omitempty"`
+	SpecificYield   float64         `json:"specific_yield_kwh_per_kwp,omitempty"`
+	CapacityFactor  float64         `json:"capacity_factor,omitempty"`
+	ByHour          []statsHourJSON `json:"by_hour"`
+	Days            []statsDayJSON  `json:"days"`
+}
+
+// statsHourJSON is the aggregate for one hour across all days
+type statsHourJSON struct {
+	Hour       int     `json:"hour"`
+	SampleDays int     `json:"sample_days"`
+	Min        float64 `json:"min_watts"`
+	Max        float64 `json:"max_watts"`
+	Average    float64 `json:"average_watts"`
+	Median     float64 `json:"median_watts"`
+	StdDev     float64 `json:"std_dev_watts"`
+}
+
+// statsDayJSON is the hourly breakdown for a single day
+type statsDayJSON struct {
+	Date  string             `json:"date"`
+	Hours []statsDayHourJSON `json:"hours"`
+}
+
+// statsDayHourJSON is the statistics for one hour of a single day
+type statsDayHourJSON struct {
+	Hour    int     `json:"hour"`
+	Samples int     `json:"samples"`
+	Min     float64 `json:"min_watts"`
+	Max     float64 `json:"max_watts"`
+	Mean    float64 `json:"mean_watts"`
+	StdDev  float64 `json:"std_dev_watts"`
+}
+
+// formatFloat formats v for CSV output with --precision decimals, or
+// defaultDecimals when --precision is not set
+func formatFloat(v float64, defaultDecimals int) string {
+	decimals := defaultDecimals
+	if precision >= 0 {
+		decimals = precision
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// roundJSON rounds v to --precision decimals for JSON output; without
+// --precision the value is written at full precision
+func roundJSON(v float64) float64 {
+	if precision < 0 {
+		return v
+	}
+	return stats.Round(v, precision)
+}
+
+// writeStatsJSON writes the multi-day statistics; peak-power metrics are
+// included when peakKW is known
+func writeStatsJSON(filename string, data *stats.MultiDayStats, days []*stats.DailyStats, peakKW float64) error {
+	out := statsJSON{
+		StartDate:       data.StartDate,
+		EndDate:         data.EndDate,
+		DaysAnalyzed:    data.DaysAnalyzed,
+		DaysWithData:    data.DaysWithData,
+		TotalProduction: roundJSON(data.TotalProduction),
+		DailyAverage:    roundJSON(data.DailyAverage),
+		PeakHour:        data.PeakHour,
+		PeakPowerAvg:    roundJSON(data.PeakPowerAvg),
+		ByHour:          []statsHourJSON{},
+		Days:            []statsDayJSON{},
+	}
+
+	if peakKW > 0 {
+		out.PeakPowerKW = peakKW
+		out.SunHours = roundJSON(data.SunHours(peakKW))
+		out.SpecificYield = roundJSON(data.SpecificYield(peakKW))
+		out.CapacityFactor = stats.Round(data.CapacityFactor(peakKW), 4)
+	}
+
+	for hour := 0; hour < 24; hour++ {
+		h := data.ByHour[hour]
+		if h == nil || h.SampleDays == 0 {
+			continue
+		}
+		out.ByHour = append(out.ByHour, statsHourJSON{
+			Hour:       hour,
+			SampleDays: h.SampleDays,
+			Min:        roundJSON(h.Min),
+			Max:        roundJSON(h.Max),
+			Average:    roundJSON(h.Average),
+			Median:     roundJSON(h.Median),
+			StdDev:     roundJSON(h.StdDev),
+		})
+	}
+
+	for _, day := range days {
+		d := statsDayJSON{Date: day.Date, Hours: []statsDayHourJSON{}}
+		for hour := 0; hour < 24; hour++ {
+			h := day.Hours[hour]
+			if h == nil || h.Samples == 0 {
+				continue
+			}
+			d.Hours = append(d.Hours, statsDayHourJSON{
+				Hour:    hour,
+				Samples: h.Samples,
+				Min:     roundJSON(h.Min),
+				Max:     roundJSON(h.Max),
+				Mean:    roundJSON(h.Mean),
+				StdDev:  roundJSON(h.StdDev),
+			})
+		}
+		out.Days = append(out.Days, d)
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
 // printASCIIGraph displays an ASCII bar chart of hourly power production
 func printASCIIGraph(dailyStats []*stats.DailyStats) {
 	const graphHeight = 15
 	const barWidth = 2
 
-	// Aggregate hourly kWh across all days
-	hourlyKWh := make([]float64, 24)
-	hourlyCounts := make([]int, 24)
+	hourlyKWh, maxKWh, totalKWh := hourlyKWhSeries(dailyStats)
 
-	for _, ds := range dailyStats {
-		for hour := 0; hour < 24; hour++ {
-			if ds.Hours[hour] != nil && ds.Hours[hour].Samples > 0 {
-				// Convert average watts to kWh (watts * 1 hour / 1000)
-				kwh := ds.Hours[hour].Mean / 1000.0
-				hourlyKWh[hour] += kwh
-				hourlyCounts[hour]++
-			}
-		}
+	if maxKWh == 0 {
+		fmt.Println("No power data to graph.")
+		return
 	}
 
-	// Average across days
+	// Print title
+	fmt.Println(graphTitle(dailyStats, totalKWh))
+	fmt.Println()
+
+	printBars(os.Stdout, hourlyKWh, maxKWh, graphHeight, barWidth)
+
+	// X-axis labels (hours)
+	fmt.Print("       ")
 	for hour := 0; hour < 24; hour++ {
-		if hourlyCounts[hour] > 0 {
-			hourlyKWh[hour] /= float64(hourlyCounts[hour])
+		if hour%3 == 0 {
+			fmt.Printf("%-6d", hour)
 		}
 	}
+	fmt.Println()
 
-	// Find max for scaling
-	maxKWh := 0.0
-	for _, kwh := range hourlyKWh {
-		if kwh > maxKWh {
-			maxKWh = kwh
-		}
-	}
+	// Legend
+	fmt.Println("       Hour of day")
+	fmt.Println()
+	fmt.Println("kWh")
+}
+
+// printDailyEnergyGraph displays an ASCII bar chart with one bar per day sized by kWh
+func printDailyEnergyGraph(dailyStats []*stats.DailyStats) {
+	const graphHeight = 15
+	const barWidth = 2
+
+	dates, dailyKWh, maxKWh, totalKWh := dailyKWhSeries(dailyStats)
 
 	if maxKWh == 0 {
-		fmt.Println("No power data to graph.")
+		fmt.Println("No energy data to graph.")
 		return
 	}
 
-	// Calculate total daily kWh
+	fmt.Printf("Daily Energy - %s to %s (Total: %.2f kWh)\n", dates[0], dates[len(dates)-1], totalKWh)
+	fmt.Println()
+
+	printBars(os.Stdout, dailyKWh, maxKWh, graphHeight, barWidth)
+
+	// X-axis labels (first and last day)
+	axisWidth := len(dailyKWh) * barWidth
+	label := dates[0]
+	if len(dates) > 1 && axisWidth > 2*len(label) {
+		label += strings.Repeat(" ", axisWidth-2*len(label)) + dates[len(dates)-1]
+	}
+	fmt.Printf("       %s\n", label)
+	fmt.Println()
+	fmt.Println("kWh per day")
+}
+
+// dailyKWhSeries returns each day's date and estimated energy, the maximum and the total
+func dailyKWhSeries(dailyStats []*stats.DailyStats) ([]string, []float64, float64, float64) {
+	dates := make([]string, 0, len(dailyStats))
+	dailyKWh := make([]float64, 0, len(dailyStats))
+	maxKWh := 0.0
 	totalKWh := 0.0
-	for _, kwh := range hourlyKWh {
+
+	for _, ds := range dailyStats {
+		kwh := ds.EnergyKWh()
+		dates = append(dates, ds.Date)
+		dailyKWh = append(dailyKWh, kwh)
+		if kwh > maxKWh {
+			maxKWh = kwh
+		}
 		totalKWh += kwh
 	}
 
-	// Print title
-	if len(dailyStats) == 1 {
-		fmt.Printf("Power Production - %s (Total: %.2f kWh)\n", dailyStats[0].Date, totalKWh)
-	} else {
-		fmt.Printf("Power Production - %d days averaged (Daily avg: %.2f kWh)\n", len(dailyStats), totalKWh)
-	}
-	fmt.Println()
+	return dates, dailyKWh, maxKWh, totalKWh
+}
 
+// printBars draws vertical bars scaled to maxVal with a labelled y-axis and an x-axis line
+func printBars(w io.Writer, values []float64, maxVal float64, height, barWidth int) {
 	// Print graph rows (top to bottom)
-	for row := graphHeight; row >= 1; row-- {
-		threshold := maxKWh * float64(row) / float64(graphHeight)
+	for row := height; row >= 1; row-- {
+		threshold := maxVal * float64(row) / float64(height)
 
 		// Y-axis label
-		if row == graphHeight {
-			fmt.Printf("%5.2f |", maxKWh)
-		} else if row == graphHeight/2+1 {
-			fmt.Printf("%5.2f |", maxKWh/2)
+		if row == height {
+			fmt.Fprintf(w, "%5.2f |", maxVal)
+		} else if row == height/2+1 {
+			fmt.Fprintf(w, "%5.2f |", maxVal/2)
 		} else if row == 1 {
-			fmt.Printf("%5.2f |", maxKWh/float64(graphHeight))
+			fmt.Fprintf(w, "%5.2f |", maxVal/float64(height))
 		} else {
-			fmt.Printf("      |")
+			fmt.Fprintf(w, "      |")
 		}
 
 		// Bars
-		for hour := 0; hour < 24; hour++ {
-			if hourlyKWh[hour] >= threshold {
-				fmt.Print(strings.Repeat("#", barWidth))
+		for _, v := range values {
+			if v >= threshold {
+				fmt.Fprint(w, strings.Repeat("#", barWidth))
 			} else {
-				fmt.Print(strings.Repeat(" ", barWidth))
+				fmt.Fprint(w, strings.Repeat(" ", barWidth))
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// X-axis line
-	fmt.Printf("      +%s\n", strings.Repeat("-", 24*barWidth))
+	fmt.Fprintf(w, "      +%s\n", strings.Repeat("-", len(values)*barWidth))
+}
 
-	// X-axis labels (hours)
-	fmt.Print("       ")
-	for hour := 0; hour < 24; hour++ {
-		if hour%3 == 0 {
-			fmt.Printf("%-6d", hour)
+// writeGraphFile renders the hourly production chart to an SVG file
+func writeGraphFile(filename string, dailyStats []*stats.DailyStats) error {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != ".svg" {
+		return fmt.Errorf("unsupported graph format %q (only .svg is supported)", ext)
+	}
+
+	const (
+		width      = 640
+		height     = 360
+		marginLeft = 60
+		marginTop  = 40
+		plotWidth  = 24 * 22
+		plotHeight = 240
+	)
+
+	hourlyKWh, maxKWh, totalKWh := hourlyKWhSeries(dailyStats)
+	if maxKWh == 0 {
+		return fmt.Errorf("no power data to graph")
+	}
+
+	// Render in memory so a failed write surfaces as an error
+	var buf bytes.Buffer
+	barWidth := float64(plotWidth) / 24
+	bottom := marginTop + plotHeight
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(&buf, `<text x="%d" y="24" font-family="sans-serif" font-size="14" text-anchor="middle">%s</text>`+"\n",
+		width/2, html.EscapeString(graphTitle(dailyStats, totalKWh)))
+
+	// Bars
+	for hour, kwh := range hourlyKWh {
+		if kwh <= 0 {
+			continue
 		}
+		barHeight := kwh / maxKWh * plotHeight
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#f5a623"/>`+"\n",
+			marginLeft+float64(hour)*barWidth+1, float64(bottom)-barHeight, barWidth-2, barHeight)
 	}
-	fmt.Println()
 
-	// Legend
-	fmt.Println("       Hour of day")
-	fmt.Println()
-	fmt.Println("kWh")
+	// Axes
+	fmt.Fprintf(&buf, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", marginLeft, marginTop, marginLeft, bottom)
+	fmt.Fprintf(&buf, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", marginLeft, bottom, marginLeft+plotWidth, bottom)
+
+	// Y-axis labels
+	for _, frac := range []float64{1, 0.5, 0} {
+		fmt.Fprintf(&buf, `<text x="%d" y="%.1f" font-family="sans-serif" font-size="11" text-anchor="end">%.2f</text>`+"\n",
+			marginLeft-6, float64(bottom)-frac*plotHeight+4, maxKWh*frac)
+	}
+	fmt.Fprintf(&buf, `<text x="16" y="%d" font-family="sans-serif" font-size="12" transform="rotate(-90 16 %d)" text-anchor="middle">kWh</text>`+"\n",
+		marginTop+plotHeight/2, marginTop+plotHeight/2)
+
+	// X-axis labels (hours)
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%d" font-family="sans-serif" font-size="11" text-anchor="middle">%d</text>`+"\n",
+			marginLeft+float64(hour)*barWidth+barWidth/2, bottom+16, hour)
+	}
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-family="sans-serif" font-size="12" text-anchor="middle">Hour of day</text>`+"\n",
+		marginLeft+plotWidth/2, bottom+36)
+
+	fmt.Fprintln(&buf, "</svg>")
+
+	return os.WriteFile(filename, buf.Bytes(), 0644)
 }
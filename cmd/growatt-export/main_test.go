@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gogrowatt/internal/stats"
 	"github.com/gogrowatt/pkg/growatt"
@@ -61,6 +66,78 @@ func TestWriteRawCSV(t *testing.T) {
 	}
 }
 
+func TestWriteEnergyCSV(t *testing.T) {
+	tmpDir := t.TempDir()
+	filename := filepath.Join(tmpDir, "test_energy.csv")
+
+	data := &growatt.EnergyData{
+		PlantID: "12345",
+		Datas: []growatt.EnergyDataPoint{
+			{Date: "2025-01-01", Energy: 28.5},
+			{Date: "2025-01-02", Energy: 32.1},
+		},
+	}
+
+	if err := writeEnergyCSV(filename, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	expected := []string{"date,kwh", "2025-01-01,28.50", "2025-01-02,32.10"}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestWriteHeatmapCSV(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "heatmap.csv")
+
+	// 2025-01-03 is a Friday; 2025-01-05 has no data
+	data := &growatt.EnergyData{
+		Datas: []growatt.EnergyDataPoint{
+			{Date: "2025-01-03", Energy: 30.8},
+			{Date: "2025-01-04", Energy: 15.2},
+			{Date: "2025-01-06", Energy: 33.2},
+		},
+	}
+	from := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	if err := writeHeatmapCSV(filename, data, from, to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	expected := []string{
+		"weekday,2024-12-29,2025-01-05",
+		"Sun,,",
+		"Mon,,33.20",
+		"Tue,,",
+		"Wed,,",
+		"Thu,,",
+		"Fri,30.80,",
+		"Sat,15.20,",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
 func TestWriteHourlyCSV(t *testing.T) {
 	tmpDir := t.TempDir()
 	filename := filepath.Join(tmpDir, "test_hourly.csv")
@@ -104,7 +181,7 @@ func TestWriteHourlyCSV(t *testing.T) {
 	}
 
 	// Check header
-	if lines[0] != "date,hour,min_watts,max_watts,avg_watts,samples" {
+	if lines[0] != "date,hour,min_watts,max_watts,avg_watts,samples,energy_kwh,coverage_pct" {
 		t.Errorf("unexpected header: %s", lines[0])
 	}
 
@@ -113,9 +190,12 @@ func TestWriteHourlyCSV(t *testing.T) {
 	for _, line := range lines[1:] {
 		if strings.HasPrefix(line, "2025-02-03,6,") {
 			found6 = true
-			if !strings.Contains(line, ",2") { // 2 samples
+			if !strings.Contains(line, ",2,") { // 2 samples
 				t.Errorf("expected 2 samples for hour 6: %s", line)
 			}
+			if !strings.HasSuffix(line, ",0.150,16.7") { // 150 W for one hour, 2 of 12 samples
+				t.Errorf("expected energy 0.150 kWh and 16.7%% coverage for hour 6: %s", line)
+			}
 			break
 		}
 	}
@@ -154,7 +234,7 @@ func TestWriteStatsMarkdown(t *testing.T) {
 	multiDay.ByHour[12].Max = 5000
 	multiDay.ByHour[12].Average = 4500
 
-	err := writeStatsMarkdown(filename, multiDay)
+	err := writeStatsMarkdown(filename, multiDay, nil, 6.7)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -187,6 +267,18 @@ func TestWriteStatsMarkdown(t *testing.T) {
 		t.Error("missing peak hour in summary")
 	}
 
+	// 33.5 kWh/day on a 6.7 kW plant
+	if !strings.Contains(contentStr, "| Equivalent Sun Hours (daily avg) | 5.00 h |") {
+		t.Error("missing sun hours in summary")
+	}
+	// 100.5 kWh over the period on 6.7 kWp; 33.5 kWh of a possible 160.8 kWh a day
+	if !strings.Contains(contentStr, "| Specific Yield | 15.00 kWh/kWp |") {
+		t.Error("missing specific yield in summary")
+	}
+	if !strings.Contains(contentStr, "| Capacity Factor | 20.8% |") {
+		t.Error("missing capacity factor in summary")
+	}
+
 	// Check hourly stats table headers
 	if !strings.Contains(contentStr, "| Hour | Min (W) | Max (W) | Average (W) | Median (W) | Std Dev | Days |") {
 		t.Error("missing hourly stats table header")
@@ -198,6 +290,94 @@ func TestWriteStatsMarkdown(t *testing.T) {
 	}
 }
 
+func TestWriteStatsJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	filename := filepath.Join(tmpDir, "test_stats.json")
+
+	var days []*stats.DailyStats
+	for i, power := range []float64{4000, 4500} {
+		day := &stats.DailyStats{Date: fmt.Sprintf("2025-02-0%d", i+1)}
+		for h := 0; h < 24; h++ {
+			day.Hours[h] = stats.NewHourlyStats(h)
+		}
+		day.Hours[12].AddValue(power)
+		for h := 0; h < 24; h++ {
+			day.Hours[h].Finalize()
+		}
+		days = append(days, day)
+	}
+
+	multiDay := stats.AggregateDays(days)
+	if err := writeStatsJSON(filename, multiDay, days, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	var result statsJSON
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+
+	if result.StartDate != "2025-02-01" || result.EndDate != "2025-02-02" {
+		t.Errorf("unexpected period: %s to %s", result.StartDate, result.EndDate)
+	}
+	if result.DaysAnalyzed != 2 {
+		t.Errorf("expected 2 days analyzed, got %d", result.DaysAnalyzed)
+	}
+
+	if len(result.ByHour) != 1 {
+		t.Fatalf("expected 1 by-hour aggregate, got %d", len(result.ByHour))
+	}
+	h := result.ByHour[0]
+	if h.Hour != 12 || h.SampleDays != 2 || h.Average != 4250 || h.Min != 4000 || h.Max != 4500 {
+		t.Errorf("unexpected by-hour aggregate: %+v", h)
+	}
+
+	if len(result.Days) != 2 || len(result.Days[1].Hours) != 1 || result.Days[1].Hours[0].Mean != 4500 {
+		t.Errorf("unexpected per-day data: %+v", result.Days)
+	}
+
+	if !strings.Contains(string(content), `"by_hour"`) {
+		t.Error("expected by_hour key in JSON")
+	}
+
+	// The output shares its keys with the stats types
+	keysOf := func(v any) map[string]any {
+		data, _ := json.Marshal(v)
+		var keys map[string]any
+		json.Unmarshal(data, &keys)
+		return keys
+	}
+	var raw struct {
+		ByHour []map[string]any `json:"by_hour"`
+		Days   []struct {
+			Hours []map[string]any `json:"hours"`
+		} `json:"days"`
+	}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	for _, check := range []struct {
+		name   string
+		got    map[string]any
+		schema map[string]any
+	}{
+		{"stats", keysOf(result), keysOf(multiDay)},
+		{"by_hour", raw.ByHour[0], keysOf(multiDay.ByHour[12])},
+		{"day hour", raw.Days[0].Hours[0], keysOf(days[0].Hours[12])},
+	} {
+		for key := range check.got {
+			if _, ok := check.schema[key]; !ok && key != "days" {
+				t.Errorf("%s: key %q is not in the stats schema", check.name, key)
+			}
+		}
+	}
+}
+
 func TestResolvePlantID_FromFlag(t *testing.T) {
 	// When flag is provided, use it directly (no API call needed)
 	client := growatt.NewClient("test-token")
@@ -348,3 +528,836 @@ func TestMultiDayOutput(t *testing.T) {
 		t.Error("raw CSV file not created")
 	}
 }
+
+func TestParseDate(t *testing.T) {
+	expected := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		layouts []string
+		wantErr bool
+	}{
+		{name: "iso", value: "2025-02-03", layouts: dateLayouts},
+		{name: "slashes year first", value: "2025/02/03", layouts: dateLayouts},
+		{name: "us format", value: "02/03/2025", layouts: dateLayouts},
+		{name: "compact", value: "20250203", layouts: dateLayouts},
+		{name: "custom layout", value: "03.02.2025", layouts: []string{"02.01.2006"}},
+		{name: "unsupported", value: "3 Feb 2025", layouts: dateLayouts, wantErr: true},
+		{name: "custom layout rejects iso", value: "2025-02-03", layouts: []string{"02.01.2006"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseDate(tt.value, tt.layouts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", result)
+				}
+				if !strings.Contains(err.Error(), "accepted formats are") {
+					t.Errorf("expected error to list accepted formats, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Equal(expected) {
+				t.Errorf("expected %v, got %v", expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteGraphFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	day := &stats.DailyStats{Date: "2025-02-03"}
+	for i := 0; i < 24; i++ {
+		day.Hours[i] = stats.NewHourlyStats(i)
+	}
+	day.Hours[9].AddValue(1500)
+	day.Hours[12].AddValue(4500)
+	for i := 0; i < 24; i++ {
+		day.Hours[i].Finalize()
+	}
+	data := []*stats.DailyStats{day}
+
+	filename := filepath.Join(tmpDir, "graph.svg")
+	if err := writeGraphFile(filename, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if len(content) == 0 {
+		t.Fatal("expected non-empty graph file")
+	}
+
+	contentStr := string(content)
+	if !strings.HasPrefix(contentStr, "<svg") {
+		t.Error("expected SVG document")
+	}
+	if !strings.Contains(contentStr, "Power Production - 2025-02-03 (Total: 6.00 kWh)") {
+		t.Error("missing title")
+	}
+	if !strings.Contains(contentStr, "Hour of day") {
+		t.Error("missing x-axis label")
+	}
+
+	// Unsupported format
+	if err := writeGraphFile(filepath.Join(tmpDir, "graph.gif"), data); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+
+	// Markup in the title is escaped
+	day.Date = `<b>&"x"`
+	if err := writeGraphFile(filename, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(filename)
+	if strings.Contains(string(content), "<b>") || !strings.Contains(string(content), "&lt;b&gt;&amp;") {
+		t.Errorf("expected the title to be escaped, got %s", content)
+	}
+
+	// Write errors are returned
+	if err := writeGraphFile(filepath.Join(tmpDir, "missing", "graph.svg"), data); err == nil {
+		t.Error("expected error writing to a missing directory")
+	}
+}
+
+func TestResolveDateRange_TodayInPlantTimezone(t *testing.T) {
+	// Machine clock in UTC just after midnight; the plant in US/Central is still on the previous day
+	now := time.Date(2025, 2, 4, 3, 30, 0, 0, time.UTC)
+
+	from, to, err := resolveDateRange([]string{"today"}, now, "US/Central")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if from.Format("2006-01-02") != "2025-02-03" {
+		t.Errorf("expected today to be 2025-02-03 in US/Central, got %s", from.Format("2006-01-02"))
+	}
+	if !from.Equal(to) {
+		t.Errorf("expected from and to to be equal, got %v and %v", from, to)
+	}
+
+	// Same instant in UTC is already the next day
+	from, _, err = resolveDateRange([]string{"today"}, now, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if from.Format("2006-01-02") != "2025-02-04" {
+		t.Errorf("expected today to be 2025-02-04 in UTC, got %s", from.Format("2006-01-02"))
+	}
+}
+
+func TestResolveDateRange_InvalidTimezone(t *testing.T) {
+	_, _, err := resolveDateRange([]string{"today"}, time.Now(), "Not/AZone")
+	if err == nil {
+		t.Fatal("expected error for invalid timezone, got nil")
+	}
+}
+
+func TestResolveTimezone(t *testing.T) {
+	t.Setenv(EnvTimezone, "")
+	if tz := resolveTimezone(""); tz != "US/Central" {
+		t.Errorf("expected default %q, got %q", "US/Central", tz)
+	}
+
+	t.Setenv(EnvTimezone, "Europe/Berlin")
+	if tz := resolveTimezone(""); tz != "Europe/Berlin" {
+		t.Errorf("expected %q from env, got %q", "Europe/Berlin", tz)
+	}
+	if tz := resolveTimezone("UTC"); tz != "UTC" {
+		t.Errorf("expected flag value %q, got %q", "UTC", tz)
+	}
+}
+
+func TestCheckRangeLimit(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		to          time.Time
+		energyMode  bool
+		confirmed   bool
+		wantErr     bool
+		wantWarning string
+	}{
+		{
+			name: "single day",
+			to:   from,
+		},
+		{
+			name: "at threshold",
+			to:   from.AddDate(0, 0, maxRangeDays-1),
+		},
+		{
+			name:    "over threshold unconfirmed",
+			to:      from.AddDate(0, 0, maxRangeDays),
+			wantErr: true,
+		},
+		{
+			name:        "over threshold confirmed",
+			to:          time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			confirmed:   true,
+			wantWarning: "~731 API requests",
+		},
+		{
+			name:        "energy mode estimates fewer requests",
+			to:          time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			energyMode:  true,
+			confirmed:   true,
+			wantWarning: "~105 API requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := checkRangeLimit(from, tt.to, tt.energyMode, tt.confirmed)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), "--yes") {
+					t.Errorf("expected error to mention --yes, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantWarning == "" && warning != "" {
+				t.Errorf("expected no warning, got %q", warning)
+			}
+			if !strings.Contains(warning, tt.wantWarning) {
+				t.Errorf("expected warning containing %q, got %q", tt.wantWarning, warning)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "single", value: "pac", expected: []string{"pac"}},
+		{name: "ordered with spaces", value: "time, Temperature ,pac", expected: []string{"time", "temperature", "pac"}},
+		{name: "unknown field", value: "time,watts", wantErr: true},
+		{name: "empty", value: ",", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := parseFields(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", columns)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(columns, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, columns)
+			}
+		})
+	}
+}
+
+// loadMINHistoryFixture reads the detailed MIN history fixture shared with the growatt package
+func loadMINHistoryFixture(t *testing.T) []growatt.MINHistoryDataPoint {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "min_history.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var resp growatt.Response[growatt.MINHistoryResponse]
+	if err := json.Unmarshal(fixture, &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	return resp.Data.Datas
+}
+
+func TestWriteFieldsCSV(t *testing.T) {
+	days := []growatt.MINHistoryDay{
+		{Serial: "ABC123456", Date: "2025-02-03", Points: loadMINHistoryFixture(t)[:3]},
+	}
+
+	filename := filepath.Join(t.TempDir(), "fields.csv")
+	if err := writeFieldsCSV(filename, days, []string{"time", "temperature", "pac", "vpv1", "date"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	expected := []string{
+		"time,temperature,pac,vpv1,date",
+		"12:05,41.20,4410.50,380.10,2025-02-03",
+		"06:00,18.50,0.00,120.00,2025-02-03",
+		"12:00,40.70,4523.50,381.00,2025-02-03",
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestWriteStringsCSV(t *testing.T) {
+	days := []growatt.MINHistoryDay{
+		{Serial: "ABC123456", Date: "2025-02-03", Points: loadMINHistoryFixture(t)},
+	}
+
+	filename := filepath.Join(t.TempDir(), "strings.csv")
+	if err := writeStringsCSV(filename, days); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	// Hour 12: (380.1*6.1 + 381.0*6.2)/2 and (375.4*6.0 + 376.0*6.1)/2
+	expected := []string{
+		"date,hour,string1_watts,string2_watts,samples",
+		"2025-02-03,6,12.00,11.82,1",
+		"2025-02-03,12,2340.41,2273.00,2",
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestDailyKWhSeries(t *testing.T) {
+	// Each day: twelve 5-minute samples at the given power, i.e. one hour
+	var days []*stats.DailyStats
+	for i, power := range []float64{4000, 2000, 6000} {
+		ds := &stats.DailyStats{Date: fmt.Sprintf("2025-02-0%d", i+1), IntervalMinutes: 5}
+		for h := range ds.Hours {
+			ds.Hours[h] = stats.NewHourlyStats(h)
+		}
+		for j := 0; j < 12; j++ {
+			ds.Hours[12].AddValue(power)
+		}
+		ds.Hours[12].Finalize()
+		days = append(days, ds)
+	}
+
+	dates, kwh, maxKWh, totalKWh := dailyKWhSeries(days)
+
+	if strings.Join(dates, ",") != "2025-02-01,2025-02-02,2025-02-03" {
+		t.Errorf("unexpected dates: %v", dates)
+	}
+	expected := []float64{4, 2, 6}
+	for i := range expected {
+		if math.Abs(kwh[i]-expected[i]) > 1e-9 {
+			t.Errorf("day %d: expected %f kWh, got %f", i, expected[i], kwh[i])
+		}
+	}
+	if math.Abs(maxKWh-6) > 1e-9 || math.Abs(totalKWh-12) > 1e-9 {
+		t.Errorf("expected max 6 and total 12, got %f and %f", maxKWh, totalKWh)
+	}
+}
+
+func TestPrintBars(t *testing.T) {
+	var buf bytes.Buffer
+	printBars(&buf, []float64{1, 2, 4}, 4, 4, 1)
+
+	expected := "" +
+		" 4.00 |  #\n" +
+		" 2.00 |  #\n" +
+		"      | ##\n" +
+		" 1.00 |###\n" +
+		"      +---\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected bars:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestUTCTimestamp(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		date  string
+		clock string
+		want  string
+	}{
+		{"before spring forward", "2025-03-09", "01:55", "2025-03-09T07:55:00Z"},
+		{"after spring forward", "2025-03-09", "03:00", "2025-03-09T08:00:00Z"},
+		{"daylight time", "2025-07-01", "12:00", "2025-07-01T17:00:00Z"},
+		{"after fall back", "2025-11-02", "02:00", "2025-11-02T08:00:00Z"},
+		{"full datetime", "2025-01-15", "2025-01-15 06:05", "2025-01-15T12:05:00Z"},
+		{"end of day", "2025-01-15", "24:00", "2025-01-16T06:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utcTimestamp(tt.date, tt.clock, chicago)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("utcTimestamp(%q, %q) = %q, want %q", tt.date, tt.clock, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := utcTimestamp("2025-01-15", "bogus", chicago); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestWriteUTCRawCSV(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	data := []growatt.PowerData{
+		{
+			Date: "2025-03-09",
+			Powers: []growatt.PowerDataPoint{
+				{Time: "01:55", Power: 0},
+				{Time: "03:00", Power: 12.5},
+			},
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "power_utc.csv")
+	if err := writeUTCRawCSV(filename, data, chicago); err != nil {
+		t.Fatalf("writeUTCRawCSV failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	want := "timestamp,power_watts\n" +
+		"2025-03-09T07:55:00Z,0.00\n" +
+		"2025-03-09T08:00:00Z,12.50\n"
+	if string(content) != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestReportedEnergyByDate(t *testing.T) {
+	data := &growatt.EnergyData{
+		Datas: []growatt.EnergyDataPoint{
+			{Date: "2025-02-01", Energy: 10.5},
+			{Date: "2025-02-02", Energy: 0},
+		},
+	}
+
+	got := reportedEnergyByDate(data)
+	if len(got) != 2 || got["2025-02-01"] != 10.5 {
+		t.Errorf("unexpected reported energy: %v", got)
+	}
+	if kwh, ok := got["2025-02-02"]; !ok || kwh != 0 {
+		t.Errorf("expected zero-energy day to be present, got %v", got)
+	}
+}
+
+func TestFetchPowerWithFallback(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		requested = append(requested, day)
+		w.Header().Set("Content-Type", "application/json")
+		if day == "2025-02-04" {
+			// Early morning: today has no readings yet
+			w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "datas": []}}`))
+			return
+		}
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "datas": [{"time": "` + day + ` 12:00:00", "pac": 4000}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token",
+		growatt.WithBaseURL(server.URL+"/"),
+		growatt.WithRateLimit(0),
+	)
+
+	today := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		fallback  bool
+		detailed  bool
+		wantDate  string
+		wantPower bool
+		wantReqs  int
+	}{
+		{"fallback to yesterday", true, false, "2025-02-03", true, 2},
+		{"fallback with telemetry", true, true, "2025-02-03", true, 2},
+		{"no fallback", false, false, "2025-02-04", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = nil
+			powerData, days, used, err := fetchPowerWithFallback(context.Background(), client, "ABC123456", today, today, "UTC", tt.detailed, tt.fallback, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if used.Format("2006-01-02") != tt.wantDate {
+				t.Errorf("expected date %s, got %s", tt.wantDate, used.Format("2006-01-02"))
+			}
+			if len(requested) != tt.wantReqs {
+				t.Errorf("expected %d requests, got %v", tt.wantReqs, requested)
+			}
+			if hasReadings(powerData) != tt.wantPower {
+				t.Errorf("expected readings %v, got %+v", tt.wantPower, powerData)
+			}
+			if tt.wantPower && powerData[0].Date != tt.wantDate {
+				t.Errorf("expected data for %s, got %s", tt.wantDate, powerData[0].Date)
+			}
+			if tt.detailed && len(days) == 0 {
+				t.Error("expected detailed telemetry days")
+			}
+		})
+	}
+}
+
+func TestWriteHourlyCSV_ZeroMinVsEmpty(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hourly.csv")
+
+	day := &stats.DailyStats{Date: "2025-02-03", IntervalMinutes: 5}
+	for i := 0; i < 24; i++ {
+		day.Hours[i] = stats.NewHourlyStats(i)
+	}
+
+	// Hour 6 has genuine readings including a 0 W sample; hour 7 has none
+	day.Hours[6].AddValue(0)
+	day.Hours[6].AddValue(120)
+	for i := 0; i < 24; i++ {
+		day.Hours[i].Finalize()
+	}
+
+	if err := writeHourlyCSV(filename, []*stats.DailyStats{day}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"zero min hour", "2025-02-03,6,0.00,120.00,60.00,2,0.060,16.7"},
+		{"empty hour", "2025-02-03,7,,,,0,0.000,0.0"},
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, line := range lines {
+				if line == tt.want {
+					return
+				}
+			}
+			t.Errorf("expected row %q in:\n%s", tt.want, content)
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	defer func() { precision = -1 }()
+
+	day := &stats.DailyStats{Date: "2025-02-03", IntervalMinutes: 5}
+	for i := 0; i < 24; i++ {
+		day.Hours[i] = stats.NewHourlyStats(i)
+	}
+	day.Hours[12].AddValue(4500.26)
+	day.Hours[12].AddValue(4499.98)
+	for i := 0; i < 24; i++ {
+		day.Hours[i].Finalize()
+	}
+	days := []*stats.DailyStats{day, day}
+
+	tests := []struct {
+		precision int
+		hourlyRow string
+		meanJSON  string
+	}{
+		{1, "2025-02-03,12,4500.0,4500.3,4500.1,2,4.5,16.7", `"mean_watts": 4500.1,`},
+		{3, "2025-02-03,12,4499.980,4500.260,4500.120,2,4.500,16.667", `"mean_watts": 4500.12,`},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("precision %d", tt.precision), func(t *testing.T) {
+			precision = tt.precision
+			tmpDir := t.TempDir()
+
+			hourlyFile := filepath.Join(tmpDir, "hourly.csv")
+			if err := writeHourlyCSV(hourlyFile, []*stats.DailyStats{day}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content, _ := os.ReadFile(hourlyFile)
+			if !strings.Contains(string(content), tt.hourlyRow+"\n") {
+				t.Errorf("expected row %q in:\n%s", tt.hourlyRow, content)
+			}
+
+			statsFile := filepath.Join(tmpDir, "stats.json")
+			if err := writeStatsJSON(statsFile, stats.AggregateDays(days), days, 0); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content, _ = os.ReadFile(statsFile)
+			if !strings.Contains(string(content), tt.meanJSON) {
+				t.Errorf("expected %q in:\n%s", tt.meanJSON, content)
+			}
+		})
+	}
+}
+
+func TestApplyConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growatt.toml")
+	content := "plant_id = \"file-plant\"\ntimezone = \"Europe/Berlin\"\ndevice_sn = \"FILE12345\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Setenv(EnvPlantID, "env-plant")
+	t.Setenv(EnvTimezone, "Asia/Tokyo")
+	t.Setenv(EnvDeviceSN, "")
+
+	defer func() { plantID, timezone, deviceSN, baseURL = "", "", "", "" }()
+
+	tests := []struct {
+		name         string
+		flagPlant    string
+		wantPlant    string
+		wantTimezone string
+	}{
+		{"file overrides env", "", "file-plant", "Europe/Berlin"},
+		{"flag overrides file", "flag-plant", "flag-plant", "Europe/Berlin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plantID, timezone, deviceSN, baseURL = tt.flagPlant, "", "", ""
+			if err := applyConfig(path); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := resolvePlantID(context.Background(), nil, plantID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantPlant {
+				t.Errorf("expected plant %q, got %q", tt.wantPlant, got)
+			}
+			if tz := resolveTimezone(timezone); tz != tt.wantTimezone {
+				t.Errorf("expected timezone %q, got %q", tt.wantTimezone, tz)
+			}
+			if deviceSN != "FILE12345" {
+				t.Errorf("expected device SN from file, got %q", deviceSN)
+			}
+			if baseURL != "" {
+				t.Errorf("expected base URL to stay unset, got %q", baseURL)
+			}
+		})
+	}
+
+	// Without the file setting, the environment still applies
+	plantID, timezone = "", ""
+	if tz := resolveTimezone(timezone); tz != "Asia/Tokyo" {
+		t.Errorf("expected env timezone, got %q", tz)
+	}
+
+	if err := applyConfig(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("expected error for missing config file")
+	}
+}
+
+func TestWriteStatsMarkdown_DailyRows(t *testing.T) {
+	var days []*stats.DailyStats
+	for i, power := range []float64{4000, 0} {
+		day := &stats.DailyStats{Date: fmt.Sprintf("2025-02-0%d", i+1)}
+		for h := 0; h < 24; h++ {
+			day.Hours[h] = stats.NewHourlyStats(h)
+		}
+		day.Hours[11].AddValue(1000)
+		if power > 0 {
+			day.Hours[12].AddValue(power)
+		}
+		for h := 0; h < 24; h++ {
+			day.Hours[h].Finalize()
+		}
+		days = append(days, day)
+	}
+
+	filename := filepath.Join(t.TempDir(), "stats.md")
+	if err := writeStatsMarkdown(filename, stats.AggregateDays(days), days, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+
+	for _, row := range []string{
+		"| Day | 11:00 | 12:00 |\n",
+		"| 2025-02-01 | 1000.0 | 4000.0 |\n",
+		"| 2025-02-02 | 1000.0 | - |\n",
+	} {
+		if !strings.Contains(string(content), row) {
+			t.Errorf("expected row %q in:\n%s", row, content)
+		}
+	}
+
+	// Without daily data the section is left out
+	if err := writeStatsMarkdown(filename, stats.AggregateDays(days), nil, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(filename)
+	if strings.Contains(string(content), "Raw Hourly Averages by Day") {
+		t.Error("expected no daily section without days")
+	}
+}
+
+func TestWriteStatsMarkdown_NoPeakPower(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stats.md")
+	multiDay := &stats.MultiDayStats{DaysAnalyzed: 2, TotalProduction: 60, DailyAverage: 30}
+
+	if err := writeStatsMarkdown(filename, multiDay, nil, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	for _, row := range []string{"Sun Hours", "Specific Yield", "Capacity Factor"} {
+		if strings.Contains(string(content), row) {
+			t.Errorf("expected no %s row without peak power", row)
+		}
+	}
+}
+
+func TestWriteStatsJSON_PeakPowerMetrics(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stats.json")
+	multiDay := &stats.MultiDayStats{DaysAnalyzed: 3, TotalProduction: 100.5, DailyAverage: 33.5}
+
+	if err := writeStatsJSON(filename, multiDay, nil, 6.7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(filename)
+	var result statsJSON
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+
+	if result.PeakPowerKW != 6.7 {
+		t.Errorf("expected peak power 6.7 kW, got %v", result.PeakPowerKW)
+	}
+	if math.Abs(result.SunHours-5) > 1e-9 {
+		t.Errorf("expected 5 sun hours, got %v", result.SunHours)
+	}
+	if math.Abs(result.SpecificYield-15) > 1e-9 {
+		t.Errorf("expected specific yield 15, got %v", result.SpecificYield)
+	}
+	if result.CapacityFactor != 0.2083 {
+		t.Errorf("expected capacity factor 0.2083, got %v", result.CapacityFactor)
+	}
+}
+
+func TestLookupPeakPower(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "plant_details.json"))
+		if err != nil {
+			t.Errorf("reading fixture: %v", err)
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+	ctx := context.Background()
+
+	// The override is used without contacting the API
+	if got := lookupPeakPower(ctx, client, "12345", 8.2); got != 8.2 {
+		t.Errorf("expected override 8.2, got %v", got)
+	}
+	if len(paths) != 0 {
+		t.Errorf("expected no requests with an override, got %v", paths)
+	}
+
+	details, err := client.GetPlantDetails(ctx, "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths = nil
+
+	if got := lookupPeakPower(ctx, client, "12345", 0); got != details.PeakPower.Float64() || got == 0 {
+		t.Errorf("expected peak power %v from plant details, got %v", details.PeakPower.Float64(), got)
+	}
+	if len(paths) != 1 || paths[0] != "/plant/details" {
+		t.Errorf("expected a single plant/details request, got %v", paths)
+	}
+
+	// Unavailable details leave the peak-power metrics out
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 10011, "error_msg": "error_permission_denied"}`))
+	}))
+	defer failing.Close()
+	failingClient := growatt.NewClient("test-token", growatt.WithBaseURL(failing.URL+"/"), growatt.WithRateLimit(0))
+	if got := lookupPeakPower(ctx, failingClient, "12345", 0); got != 0 {
+		t.Errorf("expected 0 when the details are unavailable, got %v", got)
+	}
+}
+
+func TestWriteStatsMarkdown_BestWorstDays(t *testing.T) {
+	var days []*stats.DailyStats
+	for i, power := range []float64{3000, 5000, 1000} {
+		day := &stats.DailyStats{Date: fmt.Sprintf("2025-02-0%d", i+1)}
+		for h := 0; h < 24; h++ {
+			day.Hours[h] = stats.NewHourlyStats(h)
+		}
+		day.Hours[12].AddValue(power)
+		for h := 0; h < 24; h++ {
+			day.Hours[h].Finalize()
+		}
+		days = append(days, day)
+	}
+
+	filename := filepath.Join(t.TempDir(), "stats.md")
+	if err := writeStatsMarkdown(filename, stats.AggregateDays(days), days, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(filename)
+	for _, row := range []string{"| Best Day | 2025-02-02 (5.00 kWh) |", "| Worst Day | 2025-02-03 (1.00 kWh) |"} {
+		if !strings.Contains(string(content), row) {
+			t.Errorf("expected %q in:\n%s", row, content)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogrowatt/internal/stats"
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// combinedLabel names the summed output of a multi-device export
+const combinedLabel = "combined"
+
+// parseDeviceSNs splits a comma-separated --device-sn value, dropping blanks
+// and repeated serials
+func parseDeviceSNs(value string) []string {
+	var serials []string
+	seen := make(map[string]bool)
+	for _, sn := range strings.Split(value, ",") {
+		sn = strings.TrimSpace(sn)
+		if sn == "" || seen[sn] {
+			continue
+		}
+		seen[sn] = true
+		serials = append(serials, sn)
+	}
+	return serials
+}
+
+// deviceFilename returns the path of one device's CSV of the given kind
+// (power or hourly), e.g. power_<sn>_<date>.csv
+func deviceFilename(dir, kind, label string, from, to time.Time) string {
+	dateStr := from.Format("2006-01-02")
+	if !from.Equal(to) {
+		dateStr = fmt.Sprintf("%s_to_%s", dateStr, to.Format("2006-01-02"))
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s_%s_%s.csv", kind, label, dateStr))
+}
+
+// combinePowerData sums the devices' readings at each time, day by day. Days
+// are returned in date order; a day missing for one device sums the others.
+func combinePowerData(perDevice [][]growatt.PowerData) ([]growatt.PowerData, error) {
+	byDate := make(map[string][][]growatt.PowerDataPoint)
+	var plantID growatt.FlexString
+	for _, device := range perDevice {
+		for _, day := range device {
+			byDate[day.Date] = append(byDate[day.Date], day.Powers)
+			if plantID == "" {
+				plantID = day.PlantID
+			}
+		}
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	combined := make([]growatt.PowerData, 0, len(dates))
+	for _, date := range dates {
+		powers, err := growatt.MergePowerSeries(growatt.MergeSum, byDate[date]...)
+		if err != nil {
+			return nil, fmt.Errorf("combining %s: %w", date, err)
+		}
+		combined = append(combined, growatt.PowerData{
+			PlantID: plantID,
+			Date:    date,
+			Powers:  powers,
+		})
+	}
+	return combined, nil
+}
+
+// runMultiDevice exports each device to its own raw and hourly CSVs and, with
+// --combine, the summed power of all devices
+func runMultiDevice(ctx context.Context, client *growatt.Client, serials []string, from, to time.Time, tz string, loc *time.Location) error {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return fmt.Errorf("creating output folder: %w", err)
+	}
+
+	var perDevice [][]growatt.PowerData
+	for _, sn := range serials {
+		fmt.Printf("Fetching power data for device %s from %s to %s...\n",
+			sn, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+		aggregator := startDayAggregator()
+		powerData, _, err := fetchPower(ctx, client, sn, from, to, tz, false, aggregator.add)
+		dailyStats, aggErr := aggregator.wait()
+		if err != nil {
+			return fmt.Errorf("fetching power data for device %s: %w", sn, err)
+		}
+		if aggErr != nil {
+			return aggErr
+		}
+		if !hasReadings(powerData) {
+			fmt.Fprintf(os.Stderr, "Warning: no data returned for device %s\n", sn)
+			continue
+		}
+
+		if err := writeDeviceOutputs(sn, from, to, powerData, dailyStats, loc); err != nil {
+			return err
+		}
+		perDevice = append(perDevice, powerData)
+	}
+
+	if len(perDevice) == 0 {
+		return fmt.Errorf("no data returned")
+	}
+
+	if combine {
+		combined, err := combinePowerData(perDevice)
+		if err != nil {
+			return err
+		}
+		var dailyStats []*stats.DailyStats
+		for i := range combined {
+			ds, err := aggregateDay(&combined[i])
+			if err != nil {
+				return err
+			}
+			if ds != nil {
+				dailyStats = append(dailyStats, ds)
+			}
+		}
+		if err := writeDeviceOutputs(combinedLabel, from, to, combined, dailyStats, loc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeDeviceOutputs writes the raw and hourly CSVs for one device or the
+// combined series
+func writeDeviceOutputs(label string, from, to time.Time, powerData []growatt.PowerData, dailyStats []*stats.DailyStats, loc *time.Location) error {
+	rawCSVFile := deviceFilename(folder, "power", label, from, to)
+	var err error
+	if utcTimes {
+		err = writeUTCRawCSV(rawCSVFile, powerData, loc)
+	} else {
+		err = writeRawCSV(rawCSVFile, powerData)
+	}
+	if err != nil {
+		return fmt.Errorf("writing raw CSV: %w", err)
+	}
+	fmt.Printf("Wrote raw data to %s\n", rawCSVFile)
+
+	hourlyCSVFile := deviceFilename(folder, "hourly", label, from, to)
+	if err := writeHourlyCSV(hourlyCSVFile, dailyStats); err != nil {
+		return fmt.Errorf("writing hourly CSV: %w", err)
+	}
+	fmt.Printf("Wrote hourly data to %s\n", hourlyCSVFile)
+	return nil
+}
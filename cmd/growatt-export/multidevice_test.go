@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+func TestParseDeviceSNs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"SN1", []string{"SN1"}},
+		{"SN1,SN2, SN3", []string{"SN1", "SN2", "SN3"}},
+		{"SN1,,SN2,SN1,", []string{"SN1", "SN2"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseDeviceSNs(tt.input); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("parseDeviceSNs(%q): expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestDeviceFilename(t *testing.T) {
+	day := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 2, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		kind, label string
+		to          time.Time
+		expected    string
+	}{
+		{"power", "SN1", day, filepath.Join("data", "power_SN1_2025-02-04.csv")},
+		{"hourly", "SN2", day, filepath.Join("data", "hourly_SN2_2025-02-04.csv")},
+		{"power", "SN1", end, filepath.Join("data", "power_SN1_2025-02-04_to_2025-02-06.csv")},
+		{"power", combinedLabel, end, filepath.Join("data", "power_combined_2025-02-04_to_2025-02-06.csv")},
+	}
+
+	for _, tt := range tests {
+		if got := deviceFilename("data", tt.kind, tt.label, day, tt.to); got != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestCombinePowerData(t *testing.T) {
+	device1 := []growatt.PowerData{
+		{PlantID: "12345", Date: "2025-02-04", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 1000}, {Time: "12:05", Power: 1100}}},
+		{PlantID: "12345", Date: "2025-02-05", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 900}}},
+	}
+	device2 := []growatt.PowerData{
+		{PlantID: "12345", Date: "2025-02-04", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 500}, {Time: "12:10", Power: 600}}},
+	}
+
+	combined, err := combinePowerData([][]growatt.PowerData{device1, device2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []growatt.PowerData{
+		{PlantID: "12345", Date: "2025-02-04", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 1500}, {Time: "12:05", Power: 1100}, {Time: "12:10", Power: 600}}},
+		{PlantID: "12345", Date: "2025-02-05", Powers: []growatt.PowerDataPoint{{Time: "12:00", Power: 900}}},
+	}
+	if !reflect.DeepEqual(combined, expected) {
+		t.Errorf("expected %+v, got %+v", expected, combined)
+	}
+}
+
+func TestRunMultiDevice_Combined(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		pac := map[string]string{"ABC123456": "1000", "DEF654321": "500"}[r.PostForm.Get("tlx_sn")]
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "datas": [{"time": "` + day + ` 12:00:00", "pac": ` + pac + `}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	oldFolder, oldCombine := folder, combine
+	folder, combine = t.TempDir(), true
+	defer func() { folder, combine = oldFolder, oldCombine }()
+
+	day := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+	if err := runMultiDevice(context.Background(), client, []string{"ABC123456", "DEF654321"}, day, day, "UTC", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, label := range []string{"ABC123456", "DEF654321", combinedLabel} {
+		for _, kind := range []string{"power", "hourly"} {
+			if _, err := os.Stat(deviceFilename(folder, kind, label, day, day)); err != nil {
+				t.Errorf("expected %s file for %s: %v", kind, label, err)
+			}
+		}
+	}
+
+	content, err := os.ReadFile(deviceFilename(folder, "power", combinedLabel, day, day))
+	if err != nil {
+		t.Fatalf("failed to read combined file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || lines[1] != "2025-02-04,12:00,1500.00" {
+		t.Errorf("unexpected combined output: %q", lines)
+	}
+}
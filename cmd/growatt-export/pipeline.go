@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gogrowatt/internal/stats"
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// dayAggregator parses and aggregates fetched days to hourly statistics in a
+// background goroutine, so the work overlaps with the client's rate-limit
+// wait before the next fetch. Days are processed in the order they are added.
+type dayAggregator struct {
+	in   chan growatt.PowerData
+	done chan struct{}
+
+	stats []*stats.DailyStats
+	err   error
+}
+
+// startDayAggregator starts the background goroutine; call wait to stop it
+func startDayAggregator() *dayAggregator {
+	a := &dayAggregator{
+		in:   make(chan growatt.PowerData, 1),
+		done: make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *dayAggregator) run() {
+	defer close(a.done)
+	for pd := range a.in {
+		if a.err != nil {
+			continue
+		}
+		ds, err := aggregateDay(&pd)
+		if err != nil {
+			a.err = err
+			continue
+		}
+		if ds != nil {
+			a.stats = append(a.stats, ds)
+		}
+	}
+}
+
+// add queues a day for aggregation. It must not be called after wait.
+func (a *dayAggregator) add(pd *growatt.PowerData) {
+	a.in <- *pd
+}
+
+// wait finishes the queued days and returns their statistics, skipping days
+// without readings. It is safe to call more than once.
+func (a *dayAggregator) wait() ([]*stats.DailyStats, error) {
+	select {
+	case <-a.done:
+	default:
+		close(a.in)
+		<-a.done
+	}
+	return a.stats, a.err
+}
+
+// aggregateDay converts one day of power data to hourly statistics, or nil if
+// it has no readings
+func aggregateDay(pd *growatt.PowerData) (*stats.DailyStats, error) {
+	parsed, err := growatt.ParsePowerData(pd)
+	if err != nil {
+		return nil, fmt.Errorf("parsing power data: %w", err)
+	}
+	return stats.AggregateToHourly(parsed, stats.WithMinSamples(minSamples)), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/internal/stats"
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// pipelineTestDays builds n days of 5-minute readings, the first of them empty
+func pipelineTestDays(n int) []growatt.PowerData {
+	var data []growatt.PowerData
+	for d := 0; d < n; d++ {
+		pd := growatt.PowerData{Date: fmt.Sprintf("2025-03-%02d", d+1)}
+		if d > 0 {
+			for m := 6 * 60; m < 19*60; m += 5 {
+				pd.Powers = append(pd.Powers, growatt.PowerDataPoint{
+					Time:  fmt.Sprintf("%02d:%02d", m/60, m%60),
+					Power: float64((m*7+d*13)%5000) + 0.5,
+				})
+			}
+		}
+		data = append(data, pd)
+	}
+	return data
+}
+
+// serialAggregate is the straightforward parse-then-aggregate loop the pipeline replaces
+func serialAggregate(t *testing.T, data []growatt.PowerData) []*stats.DailyStats {
+	t.Helper()
+	var result []*stats.DailyStats
+	for _, pd := range data {
+		parsed, err := growatt.ParsePowerData(&pd)
+		if err != nil {
+			t.Fatalf("parsing: %v", err)
+		}
+		if ds := stats.AggregateToHourly(parsed); ds != nil {
+			result = append(result, ds)
+		}
+	}
+	return result
+}
+
+func TestDayAggregator_MatchesSerial(t *testing.T) {
+	data := pipelineTestDays(10)
+
+	a := startDayAggregator()
+	for i := range data {
+		a.add(&data[i])
+	}
+	got, err := a.wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := serialAggregate(t, data)
+	if len(got) != 9 {
+		t.Errorf("expected 9 days with readings, got %d", len(got))
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Error("pipelined statistics differ from serial aggregation")
+	}
+
+	// A second wait returns the same result
+	again, _ := a.wait()
+	if len(again) != len(got) {
+		t.Errorf("expected repeated wait to return %d days, got %d", len(got), len(again))
+	}
+}
+
+func TestDayAggregator_ParseError(t *testing.T) {
+	a := startDayAggregator()
+	a.add(&growatt.PowerData{Date: "not-a-date"})
+	a.add(&pipelineTestDays(2)[1])
+
+	if _, err := a.wait(); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestFetchPower_PipelinedWhileRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 3, "datas": [
+			{"time": "2025-03-01 10:00:00", "pac": 1200},
+			{"time": "2025-03-01 10:05:00", "pac": 1300},
+			{"time": "2025-03-01 11:00:00", "pac": 2100}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(20*time.Millisecond))
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	a := startDayAggregator()
+	powerData, _, err := fetchPower(context.Background(), client, "ABC123456", from, to, "UTC", false, a.add)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := a.wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(powerData) != 4 {
+		t.Fatalf("expected 4 days, got %d", len(powerData))
+	}
+	if !reflect.DeepEqual(got, serialAggregate(t, powerData)) {
+		t.Error("pipelined statistics differ from serial aggregation of the fetched days")
+	}
+}
+
+func BenchmarkAggregateDay(b *testing.B) {
+	pd := pipelineTestDays(2)[1]
+	for i := 0; i < b.N; i++ {
+		if _, err := aggregateDay(&pd); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gogrowatt/pkg/growatt"
+	"github.com/spf13/cobra"
+)
+
+var listDevices bool
+
+// plantListing is one plant in the list output
+type plantListing struct {
+	PlantID      string          `json:"plant_id"`
+	PlantName    string          `json:"plant_name"`
+	Status       int             `json:"status"`
+	CurrentPower float64         `json:"current_power_watts"`
+	Devices      []deviceListing `json:"devices,omitempty"`
+}
+
+// deviceListing is one device of a plant in the list output
+type deviceListing struct {
+	DeviceSN string             `json:"device_sn"`
+	Model    string             `json:"model"`
+	Type     growatt.DeviceKind `json:"type"`
+	Status   int                `json:"status"`
+}
+
+func newListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List plants and, with --devices, their devices",
+		Long: `List the plants on the account with their IDs, names, status and current power.
+
+Examples:
+  growatt-power list
+  growatt-power list --devices
+  growatt-power list --json`,
+		RunE: runList,
+	}
+
+	listCmd.Flags().BoolVar(&listDevices, "devices", false, "Also list each plant's devices")
+
+	return listCmd
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	listings, err := fetchListings(context.Background(), client, listDevices)
+	if err != nil {
+		return err
+	}
+
+	return writeListings(os.Stdout, listings, jsonOutput)
+}
+
+// fetchListings lists all plants and, if withDevices is set, each plant's devices
+func fetchListings(ctx context.Context, client *growatt.Client, withDevices bool) ([]plantListing, error) {
+	plants, err := client.ListPlants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching plants: %w", err)
+	}
+
+	listings := make([]plantListing, 0, len(plants))
+	for _, p := range plants {
+		listing := plantListing{
+			PlantID:      p.PlantID.String(),
+			PlantName:    p.PlantName,
+			Status:       p.Status,
+			CurrentPower: p.CurrentPower.Float64(),
+		}
+
+		if withDevices {
+			devices, err := client.ListDevices(ctx, listing.PlantID)
+			if err != nil {
+				return nil, fmt.Errorf("fetching devices for plant %s: %w", listing.PlantID, err)
+			}
+			for _, d := range devices {
+				listing.Devices = append(listing.Devices, deviceListing{
+					DeviceSN: d.DeviceSN.String(),
+					Model:    d.Model,
+					Type:     d.Kind(),
+					Status:   d.Status,
+				})
+			}
+		}
+
+		listings = append(listings, listing)
+	}
+
+	return listings, nil
+}
+
+// writeListings prints the plants as an aligned table, or as a JSON array
+func writeListings(w io.Writer, listings []plantListing, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listings)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLANT ID\tNAME\tSTATUS\tPOWER (W)")
+	for _, p := range listings {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.0f\n", p.PlantID, p.PlantName, p.Status, p.CurrentPower)
+		if len(p.Devices) > 0 {
+			fmt.Fprintln(tw, "  DEVICE SN\tMODEL\tTYPE\tSTATUS")
+		}
+		for _, d := range p.Devices {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\n", d.DeviceSN, d.Model, d.Type, d.Status)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+func newListTestClient(t *testing.T) *growatt.Client {
+	t.Helper()
+	fixtures := map[string]string{
+		"/plant/list":  "plant_list.json",
+		"/device/list": "device_list_mixed.json",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", fixtures[r.URL.Path]))
+		if err != nil {
+			t.Errorf("no fixture for %s: %v", r.URL.Path, err)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+}
+
+func TestFetchListings(t *testing.T) {
+	client := newListTestClient(t)
+
+	listings, err := fetchListings(context.Background(), client, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(listings) != 2 || listings[1].PlantID != "12346" || listings[1].CurrentPower != 2100 {
+		t.Fatalf("unexpected listings: %+v", listings)
+	}
+	if listings[0].Devices != nil {
+		t.Errorf("expected no devices without --devices, got %+v", listings[0].Devices)
+	}
+
+	listings, err = fetchListings(context.Background(), client, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	devices := listings[0].Devices
+	if len(devices) != 4 {
+		t.Fatalf("expected 4 devices, got %d", len(devices))
+	}
+	if devices[0].DeviceSN != "ABC123456" || devices[0].Type != growatt.DeviceKindMIN || devices[0].Model != "MIN 9000TL-X" {
+		t.Errorf("unexpected first device: %+v", devices[0])
+	}
+}
+
+func TestWriteListings(t *testing.T) {
+	listings, err := fetchListings(context.Background(), newListTestClient(t), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var text bytes.Buffer
+	if err := writeListings(&text, listings, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	if !strings.HasPrefix(lines[0], "PLANT ID") {
+		t.Errorf("expected header, got %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "12345" || fields[len(fields)-1] != "4524" {
+		t.Errorf("unexpected plant row: %q", lines[1])
+	}
+	if !strings.Contains(text.String(), "ABC123456") || !strings.Contains(text.String(), "min") {
+		t.Errorf("expected device rows, got:\n%s", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeListings(&out, listings, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []plantListing
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(decoded) != 2 || len(decoded[1].Devices) != 4 || decoded[1].Devices[2].Type != growatt.DeviceKindStorage {
+		t.Errorf("unexpected JSON listings: %+v", decoded)
+	}
+}
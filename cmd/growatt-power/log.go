@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logDir      string
+	logInterval time.Duration
+	logTimezone string
+)
+
+func newLogCmd() *cobra.Command {
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Append current power to a daily CSV file at a fixed interval",
+		Long: `Poll the plant's current power and append timestamp,watts rows to
+power_<date>.csv in --dir, starting a new file at midnight in --timezone.
+
+Failed polls are reported on stderr and retried at the next interval.
+
+Examples:
+  growatt-power log --dir ./power-log
+  growatt-power log --dir ./power-log --interval 1m --timezone Europe/Berlin`,
+		RunE: runLog,
+	}
+
+	logCmd.Flags().StringVar(&plantID, "plant-id", "", "Plant ID (auto-detected if only one plant, or set GROWATT_PLANT_ID)")
+	logCmd.Flags().StringVar(&logDir, "dir", "", "Directory for the daily CSV files (required)")
+	logCmd.Flags().DurationVar(&logInterval, "interval", 5*time.Minute, "Polling interval")
+	logCmd.Flags().StringVar(&logTimezone, "timezone", "Local", "Timezone that decides when a new daily file starts")
+	logCmd.MarkFlagRequired("dir")
+
+	return logCmd
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	loc, err := growatt.ResolveTimezone(logTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if err := validateInterval(logInterval, client.RateLimit()); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("creating log directory: %w", err)
+	}
+
+	targetPlantID := plantID
+	if targetPlantID == "" {
+		targetPlantID = os.Getenv(EnvPlantID)
+	}
+
+	w := newDailyCSVWriter(logDir, loc)
+	defer w.Close()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(logInterval)
+	defer ticker.Stop()
+
+	logPower(os.Stderr, w, client, targetPlantID)
+	for {
+		select {
+		case <-sigChan:
+			fmt.Fprintln(os.Stderr, "\nStopping...")
+			return nil
+		case <-ticker.C:
+			logPower(os.Stderr, w, client, targetPlantID)
+		}
+	}
+}
+
+// logPower fetches one reading and appends it to w. Errors are reported to
+// errW rather than returned so a transient API failure does not stop logging.
+func logPower(errW io.Writer, w *dailyCSVWriter, client *growatt.Client, targetPlantID string) {
+	plant, err := fetchPlant(context.Background(), client, targetPlantID)
+	if err != nil {
+		fmt.Fprintf(errW, "Error: %v\n", err)
+		return
+	}
+
+	if err := w.Write(time.Now(), plant.CurrentPower.Float64()); err != nil {
+		fmt.Fprintf(errW, "Error: writing log: %v\n", err)
+	}
+}
+
+// dailyCSVWriter appends timestamp,watts rows to one CSV file per day,
+// switching files when a reading falls on a new date in loc
+type dailyCSVWriter struct {
+	dir  string
+	loc  *time.Location
+	date string
+	f    *os.File
+}
+
+func newDailyCSVWriter(dir string, loc *time.Location) *dailyCSVWriter {
+	return &dailyCSVWriter{dir: dir, loc: loc}
+}
+
+// dailyCSVPath returns the file for the given date
+func dailyCSVPath(dir, date string) string {
+	return filepath.Join(dir, fmt.Sprintf("power_%s.csv", date))
+}
+
+// Write appends one reading, rotating to the file for its date first if needed
+func (w *dailyCSVWriter) Write(t time.Time, watts float64) error {
+	local := t.In(w.loc)
+	date := local.Format("2006-01-02")
+
+	if date != w.date {
+		if err := w.open(date); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w.f, "%s,%.1f\n", local.Format(time.RFC3339), watts)
+	return err
+}
+
+// open switches to the file for date, writing the header if it is new
+func (w *dailyCSVWriter) open(date string) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(dailyCSVPath(w.dir, date), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if info.Size() == 0 {
+		if _, err := fmt.Fprintln(f, "timestamp,watts"); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	w.f = f
+	w.date = date
+	return nil
+}
+
+// Close closes the current file, if any
+func (w *dailyCSVWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	w.date = ""
+	return err
+}
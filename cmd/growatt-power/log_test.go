@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestDailyCSVWriter_RotatesAtLocalMidnight(t *testing.T) {
+	dir := t.TempDir()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	w := newDailyCSVWriter(dir, loc)
+	defer w.Close()
+
+	// 23:55 and 00:05 New York time are both Feb 4 in UTC
+	readings := []struct {
+		t     time.Time
+		watts float64
+	}{
+		{time.Date(2025, 2, 4, 4, 55, 0, 0, time.UTC), 0},
+		{time.Date(2025, 2, 4, 5, 5, 0, 0, time.UTC), 0},
+		{time.Date(2025, 2, 4, 17, 0, 0, 0, time.UTC), 4523.5},
+	}
+	for _, r := range readings {
+		if err := w.Write(r.t, r.watts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	w.Close()
+
+	day1 := readLines(t, filepath.Join(dir, "power_2025-02-03.csv"))
+	expected1 := []string{"timestamp,watts", "2025-02-03T23:55:00-05:00,0.0"}
+	if strings.Join(day1, "\n") != strings.Join(expected1, "\n") {
+		t.Errorf("day 1: expected %q, got %q", expected1, day1)
+	}
+
+	day2 := readLines(t, filepath.Join(dir, "power_2025-02-04.csv"))
+	expected2 := []string{"timestamp,watts", "2025-02-04T00:05:00-05:00,0.0", "2025-02-04T12:00:00-05:00,4523.5"}
+	if strings.Join(day2, "\n") != strings.Join(expected2, "\n") {
+		t.Errorf("day 2: expected %q, got %q", expected2, day2)
+	}
+}
+
+func TestDailyCSVWriter_AppendsToExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+
+	// A restarted logger must append without repeating the header
+	for i, watts := range []float64{100, 200} {
+		w := newDailyCSVWriter(dir, time.UTC)
+		if err := w.Write(at.Add(time.Duration(i)*5*time.Minute), watts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		w.Close()
+	}
+
+	lines := readLines(t, filepath.Join(dir, "power_2025-02-03.csv"))
+	expected := []string{"timestamp,watts", "2025-02-03T12:00:00Z,100.0", "2025-02-03T12:05:00Z,200.0"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestLogPower_ErrorDoesNotWrite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	w := newDailyCSVWriter(dir, time.UTC)
+	defer w.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	var stderr bytes.Buffer
+	logPower(&stderr, w, client, "12345")
+
+	if !strings.Contains(stderr.String(), "Error:") {
+		t.Errorf("expected error on stderr, got %q", stderr.String())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no log file after a failed poll, got %d", len(entries))
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/gogrowatt/internal/config"
 	"github.com/gogrowatt/pkg/growatt"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +29,11 @@ var (
 	baseURL      string
 	jsonOutput   bool
 	continuous   int
+	jsonFile     string
+	allPlants    bool
+	deviceSN     string
+	jitter       int
+	configFile   string
 )
 
 // PowerOutput is the JSON output structure
@@ -35,6 +46,8 @@ type PowerOutput struct {
 	PeakPower    float64 `json:"peak_power_kw"`
 	Status       int     `json:"status"`
 	Timestamp    string  `json:"timestamp,omitempty"`
+
+	Environmental growatt.EnvironmentalImpact `json:"environmental"`
 }
 
 func main() {
@@ -53,16 +66,38 @@ Examples:
   growatt-power -j
   growatt-power -c              # poll every 60 seconds
   growatt-power -c 30           # poll every 30 seconds
-  growatt-power --json | jq .current_power_watts`,
+  growatt-power -c --jitter=10  # poll every 60-70 seconds
+  growatt-power --json | jq .current_power_watts
+  growatt-power -c --json-file=power.ndjson   # text to stdout, JSON lines to file
+  growatt-power --all           # one line per plant
+  growatt-power --device-sn=ABC123456  # live power from the inverter
+  growatt-power list --devices  # plant IDs and device serials
+  growatt-power log --dir=logs  # append power to a daily CSV every 5 minutes
+  growatt-power serve           # Grafana SimpleJSON datasource on :8080`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if configFile == "" {
+				return nil
+			}
+			return applyConfig(configFile)
+		},
 		RunE: run,
 	}
 
 	rootCmd.Flags().StringVar(&plantID, "plant-id", "", "Plant ID (auto-detected if only one plant, or set GROWATT_PLANT_ID)")
-	rootCmd.Flags().StringVar(&token, "token", "", "API token (overrides GROWATT_API_KEY)")
-	rootCmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL")
-	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file supplying defaults for --plant-id and --base-url")
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "API token (overrides GROWATT_API_KEY)")
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "API base URL")
+	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+	rootCmd.Flags().StringVar(&jsonFile, "json-file", "", "Also append JSON output (one object per line, with timestamp) to this file")
+	rootCmd.Flags().BoolVar(&allPlants, "all", false, "Print power for every plant on the account")
+	rootCmd.Flags().StringVar(&deviceSN, "device-sn", "", "Read live power from this MIN/TLX inverter instead of the plant, whose value can lag")
 	rootCmd.Flags().IntVarP(&continuous, "continuous", "c", 0, "Poll continuously every N seconds (default 60 if flag used without value)")
 	rootCmd.Flag("continuous").NoOptDefVal = "60"
+	rootCmd.Flags().IntVar(&jitter, "jitter", 0, "Add a random 0..N second delay to each continuous poll interval")
+
+	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newLogCmd())
+	rootCmd.AddCommand(newServeCmd())
 
 	rootCmd.SilenceUsage = true
 
@@ -71,22 +106,49 @@ Examples:
 	}
 }
 
-func run(cmd *cobra.Command, args []string) error {
-	// Create client
-	var opts []growatt.ClientOption
+// applyConfig fills settings not given on the command line from a config
+// file. Precedence is CLI flag > config file > environment variable > default.
+func applyConfig(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	config.SetDefault(&plantID, cfg.PlantID)
+	config.SetDefault(&baseURL, cfg.BaseURL)
+	return nil
+}
+
+// newClient creates a client from the --token and --base-url flags or the environment
+func newClient() (*growatt.Client, error) {
+	opts := []growatt.ClientOption{
+		growatt.WithWarnings(func(msg string) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+		}),
+	}
 	if baseURL != "" {
 		opts = append(opts, growatt.WithBaseURL(baseURL))
 	}
 
-	var client *growatt.Client
-	var err error
 	if token != "" {
-		client = growatt.NewClient(token, opts...)
-	} else {
-		client, err = growatt.NewClientFromEnv(opts...)
-		if err != nil {
-			return fmt.Errorf("creating client: %w", err)
-		}
+		return growatt.NewClient(token, opts...), nil
+	}
+
+	client, err := growatt.NewClientFromEnv(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+	return client, nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if allPlants && deviceSN != "" {
+		return fmt.Errorf("--all cannot be combined with --device-sn")
 	}
 
 	// Resolve target plant ID once
@@ -97,96 +159,306 @@ func run(cmd *cobra.Command, args []string) error {
 
 	// If continuous mode, set up signal handling
 	if continuous > 0 {
-		return runContinuous(client, targetPlantID, time.Duration(continuous)*time.Second)
+		if err := validateInterval(time.Duration(continuous)*time.Second, client.RateLimit()); err != nil {
+			return err
+		}
+		return runContinuous(client, targetPlantID, time.Duration(continuous)*time.Second, time.Duration(jitter)*time.Second)
 	}
 
 	// Single fetch
-	return fetchAndPrint(client, targetPlantID, false)
+	return fetchAndPrint(os.Stdout, client, targetPlantID, false)
 }
 
-func runContinuous(client *growatt.Client, targetPlantID string, interval time.Duration) error {
+func runContinuous(client *growatt.Client, targetPlantID string, interval, jitter time.Duration) error {
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	// Fetches run in the background so a slow one can't delay the next tick;
+	// ticks that arrive while it is still running are dropped
+	poller := &skippingPoller{
+		fetch: func() { poll(os.Stdout, os.Stderr, client, targetPlantID) },
+		errW:  os.Stderr,
+	}
 
 	// Fetch immediately on start
-	if err := fetchAndPrint(client, targetPlantID, true); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-	}
+	poller.tick()
+
+	// A fresh timer per iteration lets each wait carry its own jitter
+	timer := time.NewTimer(nextInterval(interval, jitter, rand.Int63n))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-sigChan:
 			fmt.Fprintln(os.Stderr, "\nStopping...")
-			return nil
-		case <-ticker.C:
-			if err := fetchAndPrint(client, targetPlantID, true); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if !poller.wait(stopTimeout) {
+				fmt.Fprintln(os.Stderr, "Warning: in-flight fetch did not finish, exiting anyway")
 			}
+			return nil
+		case <-timer.C:
+			poller.tick()
+			timer.Reset(nextInterval(interval, jitter, rand.Int63n))
 		}
 	}
 }
 
-func fetchAndPrint(client *growatt.Client, targetPlantID string, includeTimestamp bool) error {
+// skippingPoller runs one fetch at a time in the background, dropping ticks
+// that arrive while the previous fetch is still in flight
+type skippingPoller struct {
+	fetch func()
+	errW  io.Writer
+
+	inFlight atomic.Bool
+
+	mu   sync.Mutex
+	done chan struct{} // Closed when the latest fetch completes
+}
+
+// tick starts a fetch unless one is already running. It returns false, and
+// reports the skip on errW, when the tick is dropped.
+func (p *skippingPoller) tick() bool {
+	if !p.inFlight.CompareAndSwap(false, true) {
+		fmt.Fprintf(p.errW, "Skipping poll at %s: previous fetch still running\n", time.Now().Format("15:04:05"))
+		return false
+	}
+
+	done := make(chan struct{})
+	p.mu.Lock()
+	p.done = done
+	p.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer p.inFlight.Store(false)
+		p.fetch()
+	}()
+	return true
+}
+
+// stopTimeout bounds how long shutdown waits for an in-flight fetch
+const stopTimeout = 10 * time.Second
+
+// wait blocks until the running fetch, if any, completes. It returns false
+// if the fetch is still running after timeout.
+func (p *skippingPoller) wait(timeout time.Duration) bool {
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done == nil {
+		return true
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// validateInterval rejects a polling interval shorter than the client's rate
+// limit, which would leave every poll waiting on or failing against the limiter
+func validateInterval(interval, rateLimit time.Duration) error {
+	if interval >= rateLimit {
+		return nil
+	}
+	safe := int(math.Ceil(rateLimit.Seconds()))
+	return fmt.Errorf("continuous interval %v is shorter than the API rate limit %v; use -c %d or more", interval, rateLimit, safe)
+}
+
+// nextInterval returns interval plus a random offset in [0, jitter], drawn with randN
+func nextInterval(interval, jitter time.Duration, randN func(int64) int64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(randN(int64(jitter)+1))
+}
+
+// errorOutput is the JSON line emitted in place of a reading when a poll fails
+type errorOutput struct {
+	Timestamp string `json:"timestamp"`
+	Error     string `json:"error"`
+}
+
+// poll fetches and prints one reading in continuous mode. Errors go to errW and,
+// in JSON mode, also to w as an error object so the stream stays valid NDJSON.
+func poll(w, errW io.Writer, client *growatt.Client, targetPlantID string) {
+	err := fetchAndPrint(w, client, targetPlantID, true)
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintf(errW, "Error: %v\n", err)
+	if jsonOutput {
+		json.NewEncoder(w).Encode(errorOutput{
+			Timestamp: time.Now().Format(time.RFC3339),
+			Error:     err.Error(),
+		})
+	}
+}
+
+func fetchAndPrint(w io.Writer, client *growatt.Client, targetPlantID string, includeTimestamp bool) error {
 	ctx := context.Background()
 
-	// Get plant list (includes current power)
-	plants, err := client.ListPlants(ctx)
+	// The plant list carries current power for every plant, so one request covers all
+	if allPlants {
+		plants, err := client.ListPlants(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching plants: %w", err)
+		}
+		if len(plants) == 0 {
+			return fmt.Errorf("no plants found for this account")
+		}
+		return writeAllOutput(w, plants, time.Now(), includeTimestamp)
+	}
+
+	var plant *growatt.Plant
+	var err error
+	if deviceSN != "" {
+		plant, err = fetchDevicePower(ctx, client, deviceSN, targetPlantID)
+	} else {
+		plant, err = fetchPlant(ctx, client, targetPlantID)
+	}
 	if err != nil {
-		return fmt.Errorf("fetching plants: %w", err)
+		return err
 	}
+	return writeOutput(w, plant, time.Now(), includeTimestamp)
+}
 
-	if len(plants) == 0 {
-		return fmt.Errorf("no plants found for this account")
+// fetchDevicePower reads the live output of a MIN/TLX inverter, which is more
+// current than the plant's reported power. The result is returned as a Plant
+// carrying the device's power and energy totals under targetPlantID.
+func fetchDevicePower(ctx context.Context, client *growatt.Client, serial, targetPlantID string) (*growatt.Plant, error) {
+	data, err := client.GetMINInverterDetails(ctx, serial)
+	if err != nil {
+		return nil, fmt.Errorf("fetching device %s: %w", serial, err)
 	}
 
-	// Find the target plant
-	var plant *growatt.Plant
+	return &growatt.Plant{
+		PlantID:      growatt.FlexString(targetPlantID),
+		CurrentPower: data.Pac,
+		TodayEnergy:  data.Etoday,
+		TotalEnergy:  data.Etotal,
+		Status:       data.Status,
+	}, nil
+}
+
+// fetchPlant returns the current reading of the target plant, auto-detecting
+// it when no plant ID is given and the account has exactly one plant
+func fetchPlant(ctx context.Context, client *growatt.Client, targetPlantID string) (*growatt.Plant, error) {
+	// A known plant is fetched directly rather than listing the whole account
 	if targetPlantID != "" {
-		for i := range plants {
-			if plants[i].PlantID.String() == targetPlantID {
-				plant = &plants[i]
-				break
-			}
-		}
-		if plant == nil {
-			return fmt.Errorf("plant %s not found", targetPlantID)
+		details, err := client.GetPlantDetails(ctx, targetPlantID)
+		if err != nil {
+			return nil, fmt.Errorf("fetching plant %s: %w", targetPlantID, err)
 		}
-	} else if len(plants) == 1 {
-		plant = &plants[0]
-	} else {
+		return &details.Plant, nil
+	}
+
+	// Get plant list (includes current power) to auto-detect the plant
+	plants, err := client.ListPlants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching plants: %w", err)
+	}
+
+	if len(plants) == 0 {
+		return nil, fmt.Errorf("no plants found for this account")
+	}
+
+	if len(plants) > 1 {
 		fmt.Fprintln(os.Stderr, "Multiple plants found:")
 		for _, p := range plants {
 			fmt.Fprintf(os.Stderr, "  - %s (ID: %s)\n", p.PlantName, p.PlantID.String())
 		}
-		return fmt.Errorf("multiple plants found; specify --plant-id or set %s environment variable", EnvPlantID)
+		return nil, fmt.Errorf("multiple plants found; specify --plant-id or set %s environment variable", EnvPlantID)
 	}
 
-	if jsonOutput {
-		output := PowerOutput{
-			PlantID:      plant.PlantID.String(),
-			PlantName:    plant.PlantName,
-			CurrentPower: plant.CurrentPower.Float64(),
-			TodayEnergy:  plant.TodayEnergy.Float64(),
-			TotalEnergy:  plant.TotalEnergy.Float64(),
-			PeakPower:    plant.PeakPower.Float64(),
-			Status:       plant.Status,
+	return &plants[0], nil
+}
+
+// newPowerOutput builds the JSON output for a plant
+func newPowerOutput(plant *growatt.Plant) PowerOutput {
+	return PowerOutput{
+		PlantID:      plant.PlantID.String(),
+		PlantName:    plant.PlantName,
+		CurrentPower: plant.CurrentPower.Float64(),
+		TodayEnergy:  plant.TodayEnergy.Float64(),
+		TotalEnergy:  plant.TotalEnergy.Float64(),
+		PeakPower:    plant.PeakPower.Float64(),
+		Status:       plant.Status,
+
+		Environmental: plant.EnvironmentalImpact(),
+	}
+}
+
+// writeOutput prints the reading to w and, if --json-file is set, appends it as NDJSON
+func writeOutput(w io.Writer, plant *growatt.Plant, now time.Time, includeTimestamp bool) error {
+	if jsonFile != "" {
+		if err := appendJSONLine(jsonFile, plant, now); err != nil {
+			return fmt.Errorf("writing JSON file: %w", err)
 		}
+	}
+
+	if jsonOutput {
+		output := newPowerOutput(plant)
 		if includeTimestamp {
-			output.Timestamp = time.Now().Format(time.RFC3339)
+			output.Timestamp = now.Format(time.RFC3339)
 		}
-		enc := json.NewEncoder(os.Stdout)
+		enc := json.NewEncoder(w)
 		return enc.Encode(output)
 	}
 
 	// Human-readable output
 	if includeTimestamp {
-		fmt.Printf("%s  %.0f W\n", time.Now().Format("15:04:05"), plant.CurrentPower.Float64())
+		fmt.Fprintf(w, "%s  %.0f W\n", now.Format("15:04:05"), plant.CurrentPower.Float64())
 	} else {
-		fmt.Printf("%.0f W\n", plant.CurrentPower.Float64())
+		fmt.Fprintf(w, "%.0f W\n", plant.CurrentPower.Float64())
+	}
+	return nil
+}
+
+// writeAllOutput prints one line per plant, or a JSON array with --json.
+// With --json-file each plant is appended as its own NDJSON line.
+func writeAllOutput(w io.Writer, plants []growatt.Plant, now time.Time, includeTimestamp bool) error {
+	if jsonFile != "" {
+		for i := range plants {
+			if err := appendJSONLine(jsonFile, &plants[i], now); err != nil {
+				return fmt.Errorf("writing JSON file: %w", err)
+			}
+		}
+	}
+
+	if jsonOutput {
+		outputs := make([]PowerOutput, 0, len(plants))
+		for i := range plants {
+			output := newPowerOutput(&plants[i])
+			if includeTimestamp {
+				output.Timestamp = now.Format(time.RFC3339)
+			}
+			outputs = append(outputs, output)
+		}
+		return json.NewEncoder(w).Encode(outputs)
+	}
+
+	for _, p := range plants {
+		if includeTimestamp {
+			fmt.Fprintf(w, "%s  ", now.Format("15:04:05"))
+		}
+		fmt.Fprintf(w, "%s (%s): %.0f W\n", p.PlantName, p.PlantID.String(), p.CurrentPower.Float64())
 	}
 	return nil
 }
+
+// appendJSONLine appends one timestamped PowerOutput to filename
+func appendJSONLine(filename string, plant *growatt.Plant, now time.Time) error {
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	output := newPowerOutput(plant)
+	output.Timestamp = now.Format(time.RFC3339)
+	return json.NewEncoder(f).Encode(output)
+}
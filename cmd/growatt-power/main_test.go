@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+func TestWriteOutput_TextAndJSONFile(t *testing.T) {
+	jsonFile = filepath.Join(t.TempDir(), "power.ndjson")
+	jsonOutput = false
+	defer func() { jsonFile = "" }()
+
+	plant := &growatt.Plant{
+		PlantID:      "12345",
+		PlantName:    "Home Solar",
+		CurrentPower: 4523.5,
+		TodayEnergy:  32.5,
+	}
+	now := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+
+	// Two polls, as in continuous mode
+	var stdout bytes.Buffer
+	for i := 0; i < 2; i++ {
+		if err := writeOutput(&stdout, plant, now.Add(time.Duration(i)*time.Minute), true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if stdout.String() != "12:00:00  4524 W\n12:01:00  4524 W\n" {
+		t.Errorf("unexpected text output: %q", stdout.String())
+	}
+
+	content, err := os.ReadFile(jsonFile)
+	if err != nil {
+		t.Fatalf("failed to read JSON file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d", len(lines))
+	}
+
+	var output PowerOutput
+	if err := json.Unmarshal([]byte(lines[1]), &output); err != nil {
+		t.Fatalf("failed to parse JSON line: %v", err)
+	}
+	if output.PlantID != "12345" || output.CurrentPower != 4523.5 {
+		t.Errorf("unexpected JSON output: %+v", output)
+	}
+	if output.Timestamp != "2025-02-03T12:01:00Z" {
+		t.Errorf("expected timestamp 2025-02-03T12:01:00Z, got %q", output.Timestamp)
+	}
+}
+
+func TestWriteAllOutput(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "plant_list.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var resp growatt.Response[growatt.PlantListData]
+	if err := json.Unmarshal(fixture, &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	plants := resp.Data.Plants
+	now := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+
+	// Text: one line per plant
+	jsonOutput = false
+	var text bytes.Buffer
+	if err := writeAllOutput(&text, plants, now, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "12:00:00  Home Solar (12345): 4524 W\n12:00:00  Office Solar (12346): 2100 W\n"
+	if text.String() != expected {
+		t.Errorf("expected %q, got %q", expected, text.String())
+	}
+
+	// JSON: a single array
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+	var out bytes.Buffer
+	if err := writeAllOutput(&out, plants, now, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var outputs []PowerOutput
+	if err := json.Unmarshal(out.Bytes(), &outputs); err != nil {
+		t.Fatalf("failed to parse JSON array: %v", err)
+	}
+	if len(outputs) != 2 || outputs[1].PlantID != "12346" || outputs[1].CurrentPower != 2100 {
+		t.Errorf("unexpected JSON output: %+v", outputs)
+	}
+}
+
+func TestPoll_JSONErrorLine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error_code": 10011, "error_msg": "error_permission_denied", "data": ""}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+
+	var stdout, stderr bytes.Buffer
+	poll(&stdout, &stderr, client, "")
+
+	var line errorOutput
+	if err := json.Unmarshal(stdout.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON error line, got %q: %v", stdout.String(), err)
+	}
+	if !strings.Contains(line.Error, "permission_denied") {
+		t.Errorf("unexpected error message: %q", line.Error)
+	}
+	if _, err := time.Parse(time.RFC3339, line.Timestamp); err != nil {
+		t.Errorf("expected RFC3339 timestamp, got %q", line.Timestamp)
+	}
+	if !strings.Contains(stderr.String(), "Error:") {
+		t.Errorf("expected error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestSkippingPoller_DropsTicksDuringSlowFetch(t *testing.T) {
+	release := make(chan struct{})
+	var running, maxRunning, fetches int32
+
+	var stderr bytes.Buffer
+	p := &skippingPoller{
+		fetch: func() {
+			n := atomic.AddInt32(&running, 1)
+			if n > atomic.LoadInt32(&maxRunning) {
+				atomic.StoreInt32(&maxRunning, n)
+			}
+			atomic.AddInt32(&fetches, 1)
+			<-release
+			atomic.AddInt32(&running, -1)
+		},
+		errW: &stderr,
+	}
+
+	if !p.tick() {
+		t.Fatal("expected the first tick to start a fetch")
+	}
+
+	// The slow fetch is still blocked, so these ticks are dropped
+	for i := 0; i < 3; i++ {
+		if p.tick() {
+			t.Errorf("tick %d: expected to be dropped while a fetch is running", i+2)
+		}
+	}
+
+	// A bounded wait gives up while the fetch is still blocked
+	if p.wait(10 * time.Millisecond) {
+		t.Error("expected wait to time out while the fetch is running")
+	}
+
+	close(release)
+	if !p.wait(time.Second) {
+		t.Fatal("expected the fetch to finish once released")
+	}
+
+	// Once the fetch completes the next tick runs again
+	if !p.tick() {
+		t.Error("expected a tick after the fetch completed to start a fetch")
+	}
+	p.wait(time.Second)
+
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected 2 fetches, got %d", n)
+	}
+	if n := atomic.LoadInt32(&maxRunning); n != 1 {
+		t.Errorf("expected fetches never to overlap, got %d at once", n)
+	}
+	if got := strings.Count(stderr.String(), "Skipping poll"); got != 3 {
+		t.Errorf("expected 3 skip messages, got %d: %q", got, stderr.String())
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	interval := 60 * time.Second
+	jitter := 15 * time.Second
+
+	// Extremes of the random source stay within [interval, interval+jitter]
+	low := nextInterval(interval, jitter, func(n int64) int64 { return 0 })
+	if low != interval {
+		t.Errorf("expected %v with zero offset, got %v", interval, low)
+	}
+	high := nextInterval(interval, jitter, func(n int64) int64 { return n - 1 })
+	if high != interval+jitter {
+		t.Errorf("expected %v with maximum offset, got %v", interval+jitter, high)
+	}
+
+	for i := 0; i < 1000; i++ {
+		got := nextInterval(interval, jitter, rand.Int63n)
+		if got < interval || got > interval+jitter {
+			t.Fatalf("interval %v outside [%v, %v]", got, interval, interval+jitter)
+		}
+	}
+
+	if got := nextInterval(interval, 0, rand.Int63n); got != interval {
+		t.Errorf("expected %v without jitter, got %v", interval, got)
+	}
+}
+
+func TestValidateInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		rateLimit time.Duration
+		wantErr   string
+	}{
+		{"below limit", 1 * time.Second, 3 * time.Second, "use -c 3 or more"},
+		{"fractional limit rounds up", 1 * time.Second, 2500 * time.Millisecond, "use -c 3 or more"},
+		{"equal to limit", 3 * time.Second, 3 * time.Second, ""},
+		{"above limit", 60 * time.Second, 3 * time.Second, ""},
+		{"no rate limit", 1 * time.Second, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInterval(tt.interval, tt.rateLimit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFetchAndPrint_KnownPlantSkipsList(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/plant/details" {
+			t.Errorf("expected only /plant/details, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("plant_id") != "12345" {
+			t.Errorf("expected plant_id 12345, got %q", r.URL.Query().Get("plant_id"))
+		}
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"plant_id": "12345", "plant_name": "Home Solar", "current_power": 4523.5, "total_energy": 15234.8}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	var buf bytes.Buffer
+	if err := fetchAndPrint(&buf, client, "12345", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Errorf("expected a single request, got %v", paths)
+	}
+	if buf.String() != "4524 W\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestFetchAndPrint_AutoDetectUsesList(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 1, "plants": [{"plant_id": "12345", "plant_name": "Home Solar", "current_power": 1200}]}}`))
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	var buf bytes.Buffer
+	if err := fetchAndPrint(&buf, client, "", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/plant/list" {
+		t.Errorf("expected a single /plant/list request, got %v", paths)
+	}
+	if buf.String() != "1200 W\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestFetchAndPrint_DeviceSNUsesDevicePower(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/device/tlx/tlx_data_info" {
+			t.Errorf("expected only the device endpoint, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("tlx_sn") != "ABC123456" {
+			t.Errorf("expected tlx_sn ABC123456, got %q", r.URL.Query().Get("tlx_sn"))
+		}
+		data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "min_inverter.json"))
+		if err != nil {
+			t.Fatalf("reading fixture: %v", err)
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(server.URL+"/"), growatt.WithRateLimit(0))
+
+	deviceSN = "ABC123456"
+	jsonOutput = true
+	defer func() { deviceSN, jsonOutput = "", false }()
+
+	var buf bytes.Buffer
+	if err := fetchAndPrint(&buf, client, "12345", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Errorf("expected a single request, got %v", paths)
+	}
+
+	var output PowerOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	if output.CurrentPower != 4523.5 || output.TodayEnergy != 32.5 || output.PlantID != "12345" {
+		t.Errorf("expected the device's pac and etoday under plant 12345, got %+v", output)
+	}
+}
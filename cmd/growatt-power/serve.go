@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+	"github.com/spf13/cobra"
+)
+
+// SimpleJSON targets served by growatt-power serve
+const (
+	TargetCurrentPower = "current_power"
+	TargetDailyEnergy  = "daily_energy"
+)
+
+// maxServeMonths caps the months a daily_energy query may span, since each
+// uncached month costs several API requests
+const maxServeMonths = 24
+
+// serveReadHeaderTimeout bounds how long a client may take to send request headers
+const serveReadHeaderTimeout = 10 * time.Second
+
+var (
+	serveAddr     string
+	serveTimezone string
+	serveCacheTTL time.Duration
+)
+
+func newServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve current power and daily energy to a Grafana SimpleJSON datasource",
+		Long: `Run an HTTP server implementing the Grafana SimpleJSON datasource API.
+
+Targets:
+  current_power  the plant's current power (W), one point at the time of the query
+  daily_energy   energy per day (kWh) in the query range, at local midnight in --timezone
+
+Current power is cached for --cache-ttl so dashboard refreshes stay within the
+API rate limit. Daily energy for past months is cached for the life of the server.
+
+Examples:
+  growatt-power serve
+  growatt-power serve --addr=127.0.0.1:9090 --timezone=Europe/Berlin`,
+		RunE: runServe,
+	}
+
+	serveCmd.Flags().StringVar(&plantID, "plant-id", "", "Plant ID (auto-detected if only one plant, or set GROWATT_PLANT_ID)")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveTimezone, "timezone", "Local", "Timezone of the plant's daily energy dates")
+	serveCmd.Flags().DurationVar(&serveCacheTTL, "cache-ttl", time.Minute, "How long a current power reading is reused")
+
+	return serveCmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	loc, err := growatt.ResolveTimezone(serveTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	targetPlantID := plantID
+	if targetPlantID == "" {
+		targetPlantID = os.Getenv(EnvPlantID)
+	}
+
+	// Resolve the plant once so energy queries know its ID
+	plant, err := fetchPlant(context.Background(), client, targetPlantID)
+	if err != nil {
+		return err
+	}
+
+	s := newSimpleJSONServer(client, plant.PlantID.String(), loc, serveCacheTTL)
+	fmt.Fprintf(os.Stderr, "Serving plant %s (%s) on %s\n", plant.PlantName, plant.PlantID.String(), serveAddr)
+	server := &http.Server{
+		Addr:              serveAddr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+// simpleJSONServer answers Grafana SimpleJSON requests from the Growatt API
+type simpleJSONServer struct {
+	client  *growatt.Client
+	plantID string
+	loc     *time.Location
+	ttl     time.Duration
+	drill   *growatt.EnergyDrillDown
+	now     func() time.Time
+
+	mu        sync.Mutex
+	power     float64
+	powerTime time.Time
+}
+
+func newSimpleJSONServer(client *growatt.Client, plantID string, loc *time.Location, ttl time.Duration) *simpleJSONServer {
+	return &simpleJSONServer{
+		client:  client,
+		plantID: plantID,
+		loc:     loc,
+		ttl:     ttl,
+		drill:   client.NewEnergyDrillDown(plantID),
+		now:     time.Now,
+	}
+}
+
+// simpleJSONQuery is the body of a /query request
+type simpleJSONQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// simpleJSONSeries is one timeseries in a /query response. Each datapoint is
+// [value, unix milliseconds].
+type simpleJSONSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+func (s *simpleJSONServer) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// The datasource's connection test expects 200 from the root
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []string{TargetCurrentPower, TargetDailyEnergy})
+	})
+
+	mux.HandleFunc("/query", func(w http.ResponseWriter, r *http.Request) {
+		var q simpleJSONQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		for _, t := range q.Targets {
+			if t.Target != TargetCurrentPower && t.Target != TargetDailyEnergy {
+				http.Error(w, fmt.Sprintf("unknown target %q", t.Target), http.StatusBadRequest)
+				return
+			}
+			if t.Target == TargetDailyEnergy {
+				if err := validateEnergyRange(q.Range.From, q.Range.To); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+
+		result := make([]simpleJSONSeries, 0, len(q.Targets))
+		for _, t := range q.Targets {
+			series, err := s.query(r.Context(), t.Target, q.Range.From, q.Range.To)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			result = append(result, series)
+		}
+		writeJSON(w, result)
+	})
+
+	return mux
+}
+
+// query returns the series for one target
+func (s *simpleJSONServer) query(ctx context.Context, target string, from, to time.Time) (simpleJSONSeries, error) {
+	series := simpleJSONSeries{Target: target, Datapoints: [][2]float64{}}
+
+	switch target {
+	case TargetCurrentPower:
+		watts, at, err := s.currentPower(ctx)
+		if err != nil {
+			return series, err
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{watts, float64(at.UnixMilli())})
+
+	case TargetDailyEnergy:
+		points, err := s.dailyEnergy(ctx, from, to)
+		if err != nil {
+			return series, err
+		}
+		series.Datapoints = points
+
+	default:
+		return series, fmt.Errorf("unknown target %q", target)
+	}
+
+	return series, nil
+}
+
+// currentPower returns the plant's current power, reusing a reading younger than the TTL
+func (s *simpleJSONServer) currentPower(ctx context.Context) (float64, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !s.powerTime.IsZero() && now.Sub(s.powerTime) < s.ttl {
+		return s.power, s.powerTime, nil
+	}
+
+	plant, err := fetchPlant(ctx, s.client, s.plantID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	s.power = plant.CurrentPowerW()
+	s.powerTime = now
+	return s.power, s.powerTime, nil
+}
+
+// dailyEnergy returns daily energy for the dates from through to in the
+// server's timezone. Past months come from the drill-down cache; the current
+// month is refetched because today's total is still growing.
+func (s *simpleJSONServer) dailyEnergy(ctx context.Context, from, to time.Time) ([][2]float64, error) {
+	from, to = from.In(s.loc), to.In(s.loc)
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, s.loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, s.loc)
+	today := s.now().In(s.loc)
+
+	points := [][2]float64{}
+	for month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, s.loc); !month.After(last); month = month.AddDate(0, 1, 0) {
+		var data *growatt.EnergyData
+		var err error
+		if month.Year() == today.Year() && month.Month() == today.Month() {
+			data, err = s.client.GetPlantEnergyRange(ctx, s.plantID, month, month.AddDate(0, 1, -1))
+		} else {
+			data, err = s.drill.Days(ctx, month.Year(), month.Month())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range data.Datas {
+			date, err := time.ParseInLocation("2006-01-02", d.Date, s.loc)
+			if err != nil || date.Before(first) || date.After(last) {
+				continue
+			}
+			points = append(points, [2]float64{d.Energy, float64(date.UnixMilli())})
+		}
+	}
+
+	return points, nil
+}
+
+// validateEnergyRange rejects a daily_energy range that is missing, inverted
+// or longer than maxServeMonths
+func validateEnergyRange(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return fmt.Errorf("invalid range: from and to are required")
+	}
+	if to.Before(from) {
+		return fmt.Errorf("invalid range: to %s is before from %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	if months > maxServeMonths {
+		return fmt.Errorf("invalid range: spans %d months, at most %d allowed", months, maxServeMonths)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
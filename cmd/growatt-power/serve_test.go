@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// newServeTestServer returns a SimpleJSON server backed by fixture data. The
+// energy fixture is only returned for the chunk starting 2025-01-01, so each
+// date appears once however the month is split into requests.
+func newServeTestServer(t *testing.T, now time.Time) (*simpleJSONServer, *int32) {
+	t.Helper()
+	var detailRequests int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := ""
+		switch r.URL.Path {
+		case "/plant/details":
+			atomic.AddInt32(&detailRequests, 1)
+			name = "plant_details.json"
+		case "/plant/energy":
+			if r.URL.Query().Get("start_date") != "2025-01-01" {
+				w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "datas": {}}}`))
+				return
+			}
+			name = "plant_energy.json"
+		}
+		data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", name))
+		if err != nil {
+			t.Errorf("no fixture for %s: %v", r.URL.Path, err)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(api.Close)
+
+	client := growatt.NewClient("test-token", growatt.WithBaseURL(api.URL+"/"), growatt.WithRateLimit(0))
+	s := newSimpleJSONServer(client, "12345", time.UTC, time.Minute)
+	s.now = func() time.Time { return now }
+	return s, &detailRequests
+}
+
+func postQuery(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServe_QueryResponseShape(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	s, _ := newServeTestServer(t, now)
+
+	rec := postQuery(t, s.handler(), `{
+		"range": {"from": "2025-01-02T00:00:00Z", "to": "2025-01-05T12:00:00Z"},
+		"targets": [{"target": "current_power"}, {"target": "daily_energy"}]
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var got []struct {
+		Target     string       `json:"target"`
+		Datapoints [][2]float64 `json:"datapoints"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("response is not SimpleJSON timeseries: %v\n%s", err, rec.Body.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 series, got %d", len(got))
+	}
+
+	if got[0].Target != TargetCurrentPower {
+		t.Errorf("expected target %q, got %q", TargetCurrentPower, got[0].Target)
+	}
+	expectedPower := [][2]float64{{4523.5, float64(now.UnixMilli())}}
+	if len(got[0].Datapoints) != 1 || got[0].Datapoints[0] != expectedPower[0] {
+		t.Errorf("current_power: expected %v, got %v", expectedPower, got[0].Datapoints)
+	}
+
+	if got[1].Target != TargetDailyEnergy {
+		t.Errorf("expected target %q, got %q", TargetDailyEnergy, got[1].Target)
+	}
+	day := func(d int) float64 {
+		return float64(time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC).UnixMilli())
+	}
+	expectedEnergy := [][2]float64{{32.1, day(2)}, {30.8, day(3)}, {15.2, day(4)}, {35.6, day(5)}}
+	if len(got[1].Datapoints) != len(expectedEnergy) {
+		t.Fatalf("daily_energy: expected %v, got %v", expectedEnergy, got[1].Datapoints)
+	}
+	for i, p := range expectedEnergy {
+		if got[1].Datapoints[i] != p {
+			t.Errorf("daily_energy[%d]: expected %v, got %v", i, p, got[1].Datapoints[i])
+		}
+	}
+}
+
+func TestServe_CurrentPowerCached(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	s, detailRequests := newServeTestServer(t, now)
+	h := s.handler()
+
+	body := `{"range": {"from": "2025-02-10T00:00:00Z", "to": "2025-02-10T12:00:00Z"}, "targets": [{"target": "current_power"}]}`
+	postQuery(t, h, body)
+	postQuery(t, h, body)
+	if n := atomic.LoadInt32(detailRequests); n != 1 {
+		t.Errorf("expected 1 details request within the TTL, got %d", n)
+	}
+
+	s.now = func() time.Time { return now.Add(2 * time.Minute) }
+	postQuery(t, h, body)
+	if n := atomic.LoadInt32(detailRequests); n != 2 {
+		t.Errorf("expected a refetch after the TTL, got %d requests", n)
+	}
+}
+
+func TestServe_SearchAndUnknownTarget(t *testing.T) {
+	s, _ := newServeTestServer(t, time.Now())
+	h := s.handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(`{"target": ""}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var targets []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &targets); err != nil {
+		t.Fatalf("unexpected /search response: %v", err)
+	}
+	if strings.Join(targets, ",") != "current_power,daily_energy" {
+		t.Errorf("unexpected targets: %v", targets)
+	}
+
+	rec = postQuery(t, h, `{"targets": [{"target": "voltage"}]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown target, got %d", rec.Code)
+	}
+}
+
+func TestServe_DailyEnergyRangeValidation(t *testing.T) {
+	now := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	s, _ := newServeTestServer(t, now)
+	h := s.handler()
+
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		valid bool
+	}{
+		{"valid", "2025-01-02T00:00:00Z", "2025-01-05T00:00:00Z", true},
+		{"missing range", "", "", false},
+		{"inverted", "2025-01-05T00:00:00Z", "2025-01-02T00:00:00Z", false},
+		{"too long", "2020-01-01T00:00:00Z", "2025-01-01T00:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rangeJSON := ""
+			if tt.from != "" {
+				rangeJSON = fmt.Sprintf(`"range": {"from": %q, "to": %q}, `, tt.from, tt.to)
+			}
+			rec := postQuery(t, h, `{`+rangeJSON+`"targets": [{"target": "daily_energy"}]}`)
+			if tt.valid && rec.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if !tt.valid && rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// current_power ignores the range
+	if rec := postQuery(t, h, `{"targets": [{"target": "current_power"}]}`); rec.Code != http.StatusOK {
+		t.Errorf("expected current_power without a range to succeed, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,99 @@
+// Package config reads CLI defaults from a simple TOML-style config file.
+//
+// The file holds one "key = value" setting per line; values may be quoted,
+// and blank lines and lines starting with # are ignored:
+//
+//	# ~/.config/growatt.toml
+//	plant_id  = "12345"
+//	device_sn = "ABC123456"
+//	timezone  = "America/Chicago"
+//	base_url  = "https://openapi-us.growatt.com/v1/"
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds the settings a config file may supply
+type Config struct {
+	PlantID  string
+	DeviceSN string
+	Timezone string
+	BaseURL  string
+}
+
+// Load reads a config file
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse reads config settings from r. Keys may use underscores or hyphens
+// (plant_id or plant-id); unknown keys are an error.
+func Parse(r io.Reader) (*Config, error) {
+	cfg := &Config{}
+	fields := map[string]*string{
+		"plant_id":  &cfg.PlantID,
+		"device_sn": &cfg.DeviceSN,
+		"timezone":  &cfg.Timezone,
+		"base_url":  &cfg.BaseURL,
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		key = strings.ReplaceAll(strings.TrimSpace(key), "-", "_")
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value %s", line, value)
+			}
+			value = unquoted
+		}
+
+		target, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown setting %q", line, key)
+		}
+		*target = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// SetDefault sets *target to value unless *target is already set, so values
+// given on the command line take precedence over the config file
+func SetDefault(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `
+# Growatt defaults
+plant_id  = "12345"
+device-sn = ABC123456
+timezone  = "America/Chicago"
+base_url  = "https://openapi-us.growatt.com/v1/"
+`
+	cfg, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Config{
+		PlantID:  "12345",
+		DeviceSN: "ABC123456",
+		Timezone: "America/Chicago",
+		BaseURL:  "https://openapi-us.growatt.com/v1/",
+	}
+	if *cfg != expected {
+		t.Errorf("expected %+v, got %+v", expected, *cfg)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing equals", "plant_id 12345"},
+		{"unknown key", `token = "secret"`},
+		{"bad quoting", `plant_id = "12345`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growatt.toml")
+	if err := os.WriteFile(path, []byte("plant_id = \"777\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PlantID != "777" {
+		t.Errorf("expected plant ID 777, got %q", cfg.PlantID)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	flagValue := "from-flag"
+	SetDefault(&flagValue, "from-file")
+	if flagValue != "from-flag" {
+		t.Errorf("expected flag value to win, got %q", flagValue)
+	}
+
+	var unset string
+	SetDefault(&unset, "from-file")
+	if unset != "from-file" {
+		t.Errorf("expected file value, got %q", unset)
+	}
+}
@@ -2,51 +2,153 @@ package stats
 
 import (
 	"math"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/gogrowatt/pkg/growatt"
 )
 
+// DefaultIntervalMinutes is the assumed sampling interval when none can be detected
+const DefaultIntervalMinutes = growatt.DefaultIntervalMinutes
+
 // HourlyStats represents statistics for a single hour
 type HourlyStats struct {
-	Hour    int
-	Samples int
-	Min     float64
-	Max     float64
-	Sum     float64
-	Mean    float64
-	StdDev  float64
-	Values  []float64 // Raw values for further calculations
+	Hour    int       `json:"hour"`
+	Samples int       `json:"samples"`
+	Min     float64   `json:"min_watts"`
+	Max     float64   `json:"max_watts"`
+	Sum     float64   `json:"sum_watts"`
+	Mean    float64   `json:"mean_watts"`
+	StdDev  float64   `json:"std_dev_watts"`
+	Values  []float64 `json:"values,omitempty"` // Raw values for further calculations
+
+	// Excluded is set when the hour has fewer samples than the aggregation's
+	// minimum; it keeps its sample count but is left out of averages and energy
+	Excluded bool `json:"excluded,omitempty"`
+}
+
+// AggregateOption configures AggregateToHourly, AggregateDays and NewAggregator
+type AggregateOption func(*aggregateOptions)
+
+type aggregateOptions struct {
+	minSamples int
+}
+
+// WithMinSamples excludes hours with fewer than n samples from averages and
+// energy, so a stray reading near dawn or dusk doesn't count as a full hour.
+// The default of 0 includes every hour with samples.
+func WithMinSamples(n int) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.minSamples = n
+	}
+}
+
+func applyAggregateOptions(opts []AggregateOption) aggregateOptions {
+	var o aggregateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// counted reports whether the hour has samples and meets the minimum count
+func (h *HourlyStats) counted(minSamples int) bool {
+	return h != nil && h.Samples > 0 && !h.Excluded && h.Samples >= minSamples
 }
 
 // DailyStats represents statistics for a single day
 type DailyStats struct {
-	Date  string
-	Hours [24]*HourlyStats
+	Date            string           `json:"date"`
+	IntervalMinutes int              `json:"interval_minutes"` // Detected sampling interval
+	Hours           [24]*HourlyStats `json:"hours"`
+}
+
+// hourEnergyKWh estimates the energy of an hour as its mean power held for
+// the whole hour. Every energy figure in this package is built from it.
+func hourEnergyKWh(h *HourlyStats) float64 {
+	// Convert W to kWh (power * 1 hour / 1000)
+	return h.Mean / 1000.0
+}
+
+// hourCoverage returns the fraction (0-1) of the samples expected in an hour at
+// the day's sampling interval that are present, capped at 1
+func (d *DailyStats) hourCoverage(h *HourlyStats) float64 {
+	interval := d.IntervalMinutes
+	if interval <= 0 {
+		interval = DefaultIntervalMinutes
+	}
+	expected := 60.0 / float64(interval)
+	return math.Min(float64(h.Samples)/expected, 1)
+}
+
+// EnergyKWh estimates the day's total energy from its hourly means, skipping
+// excluded hours. It matches the totals in MultiDayStats.
+func (d *DailyStats) EnergyKWh() float64 {
+	return integratedEnergyKWh(d, 0)
+}
+
+// SunHours returns the day's equivalent full-power hours for a plant of peakKW
+func (d *DailyStats) SunHours(peakKW float64) float64 {
+	return SunHours(d.EnergyKWh(), peakKW)
 }
 
 // AggregatedHourStats represents stats for an hour across multiple days
 type AggregatedHourStats struct {
-	Hour       int
-	SampleDays int
-	Min        float64 // Minimum of all values at this hour across days
-	Max        float64 // Maximum of all values at this hour across days
-	Average    float64 // Average of all values at this hour across days
-	Median     float64 // Median of hourly averages
-	StdDev     float64 // Standard deviation of hourly averages
-	Values     []float64
+	Hour       int       `json:"hour"`
+	SampleDays int       `json:"sample_days"`
+	Min        float64   `json:"min_watts"`        // Minimum of all values at this hour across days
+	Max        float64   `json:"max_watts"`        // Maximum of all values at this hour across days
+	Average    float64   `json:"average_watts"`    // Average of all values at this hour across days
+	Median     float64   `json:"median_watts"`     // Median of hourly averages
+	StdDev     float64   `json:"std_dev_watts"`    // Standard deviation of hourly averages
+	Values     []float64 `json:"values,omitempty"` // Hourly mean of each covered day
 }
 
 // MultiDayStats represents statistics across multiple days
 type MultiDayStats struct {
-	StartDate       string
-	EndDate         string
-	DaysAnalyzed    int
-	ByHour          [24]*AggregatedHourStats
-	TotalProduction float64
-	DailyAverage    float64
-	PeakHour        int
-	PeakPowerAvg    float64
+	StartDate       string                   `json:"start_date"`
+	EndDate         string                   `json:"end_date"`
+	DaysAnalyzed    int                      `json:"days_analyzed"`
+	DaysWithData    int                      `json:"days_with_data"` // Days with at least one reading; DailyAverage is taken over these
+	ByHour          [24]*AggregatedHourStats `json:"by_hour"`
+	TotalProduction float64                  `json:"total_production_kwh"`
+	DailyAverage    float64                  `json:"daily_average_kwh"`
+	PeakHour        int                      `json:"peak_hour"` // Hour with the highest Average; ties go to the earliest hour
+	PeakPowerAvg    float64                  `json:"peak_power_avg_watts"`
+}
+
+// SunHours returns equivalent full-power hours: daily energy (kWh) divided by
+// peak power (kW). It is 0 when the peak power is unknown.
+func SunHours(energyKWh, peakKW float64) float64 {
+	if peakKW <= 0 {
+		return 0
+	}
+	return energyKWh / peakKW
+}
+
+// SunHours returns the average daily equivalent full-power hours for a plant of peakKW
+func (m *MultiDayStats) SunHours(peakKW float64) float64 {
+	return SunHours(m.DailyAverage, peakKW)
+}
+
+// SpecificYield returns the period's production per kW of peak power (kWh/kWp).
+// It is 0 when the peak power is unknown.
+func (m *MultiDayStats) SpecificYield(peakKW float64) float64 {
+	if peakKW <= 0 {
+		return 0
+	}
+	return m.TotalProduction / peakKW
+}
+
+// CapacityFactor returns average daily production as a fraction of what the
+// peak power would produce running all 24 hours. It is 0 when the peak power
+// is unknown.
+func (m *MultiDayStats) CapacityFactor(peakKW float64) float64 {
+	if peakKW <= 0 {
+		return 0
+	}
+	return m.DailyAverage / (peakKW * 24)
 }
 
 // NewHourlyStats creates a new HourlyStats for the given hour
@@ -101,6 +203,30 @@ func CalculateStdDev(values []float64, mean float64) float64 {
 	return math.Sqrt(variance)
 }
 
+// Round rounds v to the given number of decimal places
+func Round(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
+
+// DiffCumulative converts readings of a cumulative counter such as Etotal into
+// per-interval increments, returning one value per consecutive pair. A
+// decrease is treated as a counter reset: it starts a new segment with an
+// increment of 0 rather than producing negative production.
+func DiffCumulative(values []float64) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+
+	diffs := make([]float64, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if d := values[i] - values[i-1]; d > 0 {
+			diffs[i-1] = d
+		}
+	}
+	return diffs
+}
+
 // CalculateMedian calculates the median of a slice of values
 func CalculateMedian(values []float64) float64 {
 	if len(values) == 0 {
@@ -109,8 +235,13 @@ func CalculateMedian(values []float64) float64 {
 
 	sorted := make([]float64, len(values))
 	copy(sorted, values)
-	sort.Float64s(sorted)
+	slices.Sort(sorted)
+
+	return medianOfSorted(sorted)
+}
 
+// medianOfSorted returns the median of an already sorted, non-empty slice
+func medianOfSorted(sorted []float64) float64 {
 	n := len(sorted)
 	if n%2 == 0 {
 		return (sorted[n/2-1] + sorted[n/2]) / 2
@@ -119,13 +250,15 @@ func CalculateMedian(values []float64) float64 {
 }
 
 // AggregateToHourly converts 5-minute power data to hourly statistics
-func AggregateToHourly(data []growatt.ParsedPowerData) *DailyStats {
+func AggregateToHourly(data []growatt.ParsedPowerData, opts ...AggregateOption) *DailyStats {
 	if len(data) == 0 {
 		return nil
 	}
+	o := applyAggregateOptions(opts)
 
 	stats := &DailyStats{
-		Date: data[0].Date.Format("2006-01-02"),
+		Date:            data[0].Date.Format("2006-01-02"),
+		IntervalMinutes: DetectIntervalMinutes(data),
 	}
 
 	// Initialize all hours
@@ -142,60 +275,131 @@ func AggregateToHourly(data []growatt.ParsedPowerData) *DailyStats {
 
 	// Finalize all hours
 	for i := 0; i < 24; i++ {
-		stats.Hours[i].Finalize()
+		h := stats.Hours[i]
+		h.Finalize()
+		h.Excluded = h.Samples > 0 && h.Samples < o.minSamples
 	}
 
 	return stats
 }
 
+// DetectIntervalMinutes returns the smallest gap between consecutive readings,
+// falling back to DefaultIntervalMinutes when it cannot be determined
+func DetectIntervalMinutes(data []growatt.ParsedPowerData) int {
+	minutes := make([]int, 0, len(data))
+	for _, p := range data {
+		minutes = append(minutes, p.Hour*60+p.Minute)
+	}
+	return growatt.DetectIntervalMinutes(minutes)
+}
+
 // AggregateDays combines statistics from multiple days
-func AggregateDays(days []*DailyStats) *MultiDayStats {
+func AggregateDays(days []*DailyStats, opts ...AggregateOption) *MultiDayStats {
 	if len(days) == 0 {
 		return nil
 	}
+	o := applyAggregateOptions(opts)
 
-	result := &MultiDayStats{
-		StartDate:    days[0].Date,
-		EndDate:      days[len(days)-1].Date,
-		DaysAnalyzed: len(days),
+	// Count the days covering each hour so every Values slice is allocated once
+	var covered [24]int
+	for _, day := range days {
+		for hour := 0; hour < 24; hour++ {
+			if day.Hours[hour].counted(o.minSamples) {
+				covered[hour]++
+			}
+		}
+	}
+
+	a := newAggregator(covered, o)
+	for _, day := range days {
+		a.AddDay(day)
 	}
+	return a.Result()
+}
+
+// Aggregator builds MultiDayStats one day at a time, so a long range can be
+// aggregated without holding every day's DailyStats. Only the hourly means
+// needed for the medians are retained.
+type Aggregator struct {
+	result     *MultiDayStats
+	minSamples int
+}
 
-	// Initialize hourly aggregates
+// NewAggregator creates an empty Aggregator
+func NewAggregator(opts ...AggregateOption) *Aggregator {
+	return newAggregator([24]int{}, applyAggregateOptions(opts))
+}
+
+// newAggregator creates an Aggregator with Values capacity for each hour
+func newAggregator(capacity [24]int, o aggregateOptions) *Aggregator {
+	result := &MultiDayStats{}
 	for i := 0; i < 24; i++ {
 		result.ByHour[i] = &AggregatedHourStats{
 			Hour:   i,
 			Min:    math.MaxFloat64,
 			Max:    -math.MaxFloat64,
-			Values: make([]float64, 0),
+			Values: make([]float64, 0, capacity[i]),
 		}
 	}
+	return &Aggregator{result: result, minSamples: o.minSamples}
+}
 
-	// Aggregate data from all days
-	for _, day := range days {
-		for hour := 0; hour < 24; hour++ {
-			hourStats := day.Hours[hour]
-			if hourStats == nil || hourStats.Samples == 0 {
-				continue
-			}
+// AddDay adds one day's statistics. Days must be added in date order.
+func (a *Aggregator) AddDay(day *DailyStats) {
+	result := a.result
+	if result.DaysAnalyzed == 0 {
+		result.StartDate = day.Date
+	}
+	result.EndDate = day.Date
+	result.DaysAnalyzed++
+	if hasSamples(day) {
+		result.DaysWithData++
+	}
 
-			agg := result.ByHour[hour]
-			agg.SampleDays++
+	for hour := 0; hour < 24; hour++ {
+		hourStats := day.Hours[hour]
+		if !hourStats.counted(a.minSamples) {
+			continue
+		}
 
-			// Track min/max across all individual readings
-			if hourStats.Min < agg.Min {
-				agg.Min = hourStats.Min
-			}
-			if hourStats.Max > agg.Max {
-				agg.Max = hourStats.Max
-			}
+		agg := result.ByHour[hour]
+		agg.SampleDays++
 
-			// Store hourly means for aggregation
-			agg.Values = append(agg.Values, hourStats.Mean)
+		// Track min/max across all individual readings
+		if hourStats.Min < agg.Min {
+			agg.Min = hourStats.Min
+		}
+		if hourStats.Max > agg.Max {
+			agg.Max = hourStats.Max
 		}
+
+		// Store hourly means for aggregation
+		agg.Values = append(agg.Values, hourStats.Mean)
 	}
 
-	// Calculate final statistics for each hour
+	// Total production is estimated from power
+	result.TotalProduction += integratedEnergyKWh(day, a.minSamples)
+}
+
+// Result finalizes and returns the statistics, or nil if no days were added.
+// No further days may be added afterwards.
+func (a *Aggregator) Result() *MultiDayStats {
+	result := a.result
+	if result.DaysAnalyzed == 0 {
+		return nil
+	}
+
+	maxCovered := 0
+	for _, agg := range result.ByHour {
+		if len(agg.Values) > maxCovered {
+			maxCovered = len(agg.Values)
+		}
+	}
+
+	// Calculate final statistics for each hour, sorting for the median in a
+	// buffer shared across hours
 	var maxAvg float64
+	sorted := make([]float64, 0, maxCovered)
 	for hour := 0; hour < 24; hour++ {
 		agg := result.ByHour[hour]
 
@@ -211,9 +415,12 @@ func AggregateDays(days []*DailyStats) *MultiDayStats {
 			sum += v
 		}
 		agg.Average = sum / float64(len(agg.Values))
-		agg.Median = CalculateMedian(agg.Values)
+		sorted = append(sorted[:0], agg.Values...)
+		slices.Sort(sorted)
+		agg.Median = medianOfSorted(sorted)
 		agg.StdDev = CalculateStdDev(agg.Values, agg.Average)
 
+		// Strictly greater, so of hours with equal averages the earliest is the peak
 		if agg.Average > maxAvg {
 			maxAvg = agg.Average
 			result.PeakHour = hour
@@ -221,36 +428,300 @@ func AggregateDays(days []*DailyStats) *MultiDayStats {
 		}
 	}
 
-	// Calculate total and daily average production (estimated from power)
-	// Assuming each hourly reading represents average power for that hour
+	if result.DaysWithData > 0 {
+		result.DailyAverage = result.TotalProduction / float64(result.DaysWithData)
+	}
+
+	return result
+}
+
+// TypicalDayLabel is the Date of the synthetic day returned by TypicalDay
+const TypicalDayLabel = "typical"
+
+// TypicalDay builds a representative day by averaging each hour across days.
+// An hour is averaged only over the days that have samples for it, so days with
+// differing coverage do not drag the profile towards zero. The hour's Values are
+// the per-day means, making Min, Max and StdDev the day-to-day spread, and its
+// Samples are the average coverage.
+func TypicalDay(days []*DailyStats) *DailyStats {
+	if len(days) == 0 {
+		return nil
+	}
+
+	// Use the finest sampling interval among the days
+	interval := 0
 	for _, day := range days {
-		var dailyEnergy float64
-		for hour := 0; hour < 24; hour++ {
-			if day.Hours[hour] != nil {
-				// Convert W to kWh (power * 1 hour / 1000)
-				dailyEnergy += day.Hours[hour].Mean / 1000.0
+		if day.IntervalMinutes > 0 && (interval == 0 || day.IntervalMinutes < interval) {
+			interval = day.IntervalMinutes
+		}
+	}
+	if interval == 0 {
+		interval = DefaultIntervalMinutes
+	}
+
+	result := &DailyStats{Date: TypicalDayLabel, IntervalMinutes: interval}
+	for hour := 0; hour < 24; hour++ {
+		h := NewHourlyStats(hour)
+
+		var coveredMinutes float64
+		for _, day := range days {
+			dh := day.Hours[hour]
+			if !dh.counted(0) {
+				continue
+			}
+			dayInterval := day.IntervalMinutes
+			if dayInterval <= 0 {
+				dayInterval = DefaultIntervalMinutes
 			}
+			coveredMinutes += float64(dh.Samples * dayInterval)
+			h.AddValue(dh.Mean)
+		}
+
+		covered := h.Samples
+		h.Finalize()
+		if covered > 0 {
+			h.Samples = int(math.Round(coveredMinutes / float64(covered) / float64(interval)))
 		}
-		result.TotalProduction += dailyEnergy
+		result.Hours[hour] = h
 	}
 
-	if result.DaysAnalyzed > 0 {
-		result.DailyAverage = result.TotalProduction / float64(result.DaysAnalyzed)
+	return result
+}
+
+// integratedEnergyKWh estimates a day's energy from power, assuming each
+// hourly mean represents the average power for that hour. Excluded hours and
+// hours with fewer than minSamples samples are skipped.
+func integratedEnergyKWh(day *DailyStats, minSamples int) float64 {
+	var energy float64
+	for hour := 0; hour < 24; hour++ {
+		if h := day.Hours[hour]; h != nil && !h.Excluded && h.Samples >= minSamples {
+			energy += hourEnergyKWh(h)
+		}
+	}
+	return energy
+}
+
+// BestWorstDays returns the days with the highest and lowest energy, estimated
+// the same way as TotalProduction. Days without readings are skipped, and ties
+// go to the earliest date. best and worst are nil if no day has readings.
+func BestWorstDays(days []*DailyStats) (best, worst *DailyStats, bestKWh, worstKWh float64) {
+	for _, day := range days {
+		if !hasSamples(day) {
+			continue
+		}
+
+		kwh := integratedEnergyKWh(day, 0)
+		if best == nil || kwh > bestKWh || (kwh == bestKWh && day.Date < best.Date) {
+			best, bestKWh = day, kwh
+		}
+		if worst == nil || kwh < worstKWh || (kwh == worstKWh && day.Date < worst.Date) {
+			worst, worstKWh = day, kwh
+		}
+	}
+	return best, worst, bestKWh, worstKWh
+}
+
+// UseReportedEnergy recomputes TotalProduction and DailyAverage from the
+// inverter's reported daily energy (kWh keyed by YYYY-MM-DD date). Days without
+// a reported value fall back to integrating power. It returns the number of
+// days that used the reported value.
+func (m *MultiDayStats) UseReportedEnergy(days []*DailyStats, reported map[string]float64) int {
+	var used, counted int
+	m.TotalProduction = 0
+	for _, day := range days {
+		if kwh, ok := reported[day.Date]; ok {
+			m.TotalProduction += kwh
+			used++
+			counted++
+			continue
+		}
+		m.TotalProduction += integratedEnergyKWh(day, 0)
+		if hasSamples(day) {
+			counted++
+		}
+	}
+
+	if counted > 0 {
+		m.DailyAverage = m.TotalProduction / float64(counted)
 	}
+	return used
+}
+
+// hasSamples reports whether any hour of the day has a reading
+func hasSamples(day *DailyStats) bool {
+	for _, h := range day.Hours {
+		if h != nil && h.Samples > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ClippingTolerance is the fraction below the cap at which a sample still counts as saturated
+const ClippingTolerance = 0.01
+
+// ClippedInterval is a run of consecutive saturated samples
+type ClippedInterval struct {
+	Start   string // HH:MM of the first saturated sample
+	End     string // HH:MM of the last saturated sample
+	Samples int
+}
+
+// ClippingResult summarizes inverter saturation for a set of readings
+type ClippingResult struct {
+	Intervals        []ClippedInterval
+	SaturatedSamples int
+	Duration         time.Duration // Total time spent at the cap
+}
+
+// DetectClipping flags samples at or near capW (the inverter rating in watts).
+// The energy lost above the cap cannot be known, so only saturated samples and
+// their duration are reported.
+func DetectClipping(points []growatt.ParsedPowerData, capW float64) ClippingResult {
+	var result ClippingResult
+	if len(points) == 0 || capW <= 0 {
+		return result
+	}
+
+	sorted := make([]growatt.ParsedPowerData, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Hour*60+sorted[i].Minute < sorted[j].Hour*60+sorted[j].Minute
+	})
+
+	threshold := capW * (1 - ClippingTolerance)
+	var current *ClippedInterval
+	for _, p := range sorted {
+		if p.Power < threshold {
+			current = nil
+			continue
+		}
+
+		result.SaturatedSamples++
+		if current == nil {
+			result.Intervals = append(result.Intervals, ClippedInterval{Start: p.Time})
+			current = &result.Intervals[len(result.Intervals)-1]
+		}
+		current.End = p.Time
+		current.Samples++
+	}
+
+	interval := time.Duration(DetectIntervalMinutes(points)) * time.Minute
+	result.Duration = time.Duration(result.SaturatedSamples) * interval
 
 	return result
 }
 
-// HourlyRow represents a single row in the hourly output
-type HourlyRow struct {
-	Date    string
+// StringHourlyStats is the mean DC power of each MPPT input (string) for one hour
+type StringHourlyStats struct {
 	Hour    int
-	Min     float64
-	Max     float64
-	Avg     float64
+	String1 float64 // Mean Vpv1*Ipv1 (W)
+	String2 float64 // Mean Vpv2*Ipv2 (W)
 	Samples int
 }
 
+// AggregateStringsHourly computes per-string hourly mean power from detailed
+// MIN history. Points with unparseable times are skipped; only hours with
+// samples are returned, in order.
+func AggregateStringsHourly(points []growatt.MINHistoryDataPoint) []StringHourlyStats {
+	var hours [24]StringHourlyStats
+	for _, p := range points {
+		t, err := time.Parse("15:04", p.ClockTime())
+		if err != nil {
+			continue
+		}
+		pv1, pv2 := p.StringPower()
+		h := &hours[t.Hour()]
+		h.String1 += pv1
+		h.String2 += pv2
+		h.Samples++
+	}
+
+	var result []StringHourlyStats
+	for hour, h := range hours {
+		if h.Samples == 0 {
+			continue
+		}
+		result = append(result, StringHourlyStats{
+			Hour:    hour,
+			String1: h.String1 / float64(h.Samples),
+			String2: h.String2 / float64(h.Samples),
+			Samples: h.Samples,
+		})
+	}
+
+	return result
+}
+
+// EstimateThermalLoss estimates the energy (kWh) lost to thermal derating.
+// This is a rough model: at each reading above thresholdC the inverter is
+// assumed to lose coeffPerC (a fraction, e.g. 0.005 for 0.5%) of its AC
+// output per degree over the threshold, and that loss is held for one
+// sampling interval. Readings with unparseable times are skipped.
+func EstimateThermalLoss(points []growatt.MINHistoryDataPoint, thresholdC, coeffPerC float64) float64 {
+	parsed := make([]growatt.ParsedPowerData, 0, len(points))
+	hot := make([]float64, 0, len(points)) // Estimated lost power (W) per reading
+	for _, p := range points {
+		t, err := time.Parse("15:04", p.ClockTime())
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, growatt.ParsedPowerData{Hour: t.Hour(), Minute: t.Minute()})
+
+		excess := p.Temperature.Float64() - thresholdC
+		if excess <= 0 || coeffPerC <= 0 {
+			continue
+		}
+		hot = append(hot, p.Pac.Float64()*math.Min(excess*coeffPerC, 1))
+	}
+
+	hours := float64(DetectIntervalMinutes(parsed)) / 60.0
+	var lostKWh float64
+	for _, w := range hot {
+		lostKWh += w * hours / 1000.0
+	}
+
+	return lostKWh
+}
+
+// HourlyRow represents a single row in the hourly output
+type HourlyRow struct {
+	Date    string  `json:"date"`
+	Hour    int     `json:"hour"`
+	Min     float64 `json:"min_watts"`
+	Max     float64 `json:"max_watts"`
+	Avg     float64 `json:"avg_watts"`
+	Samples int     `json:"samples"`
+	Energy  float64 `json:"energy_kwh"`
+
+	CoveragePct float64 `json:"coverage_pct"` // Percentage (0-100) of expected samples present
+}
+
+// HourlyKWhSeries averages each hour's energy across the days that have
+// readings for it, treating the hourly mean power as one hour of production
+func HourlyKWhSeries(days []*DailyStats) [24]float64 {
+	var series [24]float64
+	var counts [24]int
+
+	for _, day := range days {
+		for hour := 0; hour < 24; hour++ {
+			if h := day.Hours[hour]; h.counted(0) {
+				// Convert average watts to kWh (watts * 1 hour / 1000)
+				series[hour] += h.Mean / 1000.0
+				counts[hour]++
+			}
+		}
+	}
+
+	for hour := 0; hour < 24; hour++ {
+		if counts[hour] > 0 {
+			series[hour] /= float64(counts[hour])
+		}
+	}
+
+	return series
+}
+
 // GetHourlyRows returns all hourly data as rows for CSV export
 func GetHourlyRows(days []*DailyStats) []HourlyRow {
 	var rows []HourlyRow
@@ -268,9 +739,89 @@ func GetHourlyRows(days []*DailyStats) []HourlyRow {
 				Max:     h.Max,
 				Avg:     h.Mean,
 				Samples: h.Samples,
+				Energy:  hourEnergyKWh(h),
+
+				CoveragePct: day.hourCoverage(h) * 100,
 			})
 		}
 	}
 
 	return rows
 }
+
+// ProductionHeatmap indexes daily energy (kWh) by date for a calendar heatmap
+func ProductionHeatmap(energy *growatt.EnergyData) map[string]float64 {
+	heatmap := make(map[string]float64, len(energy.Datas))
+	for _, d := range energy.Datas {
+		heatmap[d.Date] += d.Energy
+	}
+	return heatmap
+}
+
+// HeatmapWeek is one column of a calendar heatmap. Days holds the dates
+// (YYYY-MM-DD) from Sunday to Saturday; days outside the range are empty.
+type HeatmapWeek struct {
+	Start time.Time
+	Days  [7]string
+}
+
+// HeatmapWeeks splits the dates from through to into Sunday-first weeks
+func HeatmapWeeks(from, to time.Time) []HeatmapWeek {
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, from.Location())
+
+	var weeks []HeatmapWeek
+	for start := first.AddDate(0, 0, -int(first.Weekday())); !start.After(last); start = start.AddDate(0, 0, 7) {
+		week := HeatmapWeek{Start: start}
+		for i := range week.Days {
+			day := start.AddDate(0, 0, i)
+			if !day.Before(first) && !day.After(last) {
+				week.Days[i] = day.Format("2006-01-02")
+			}
+		}
+		weeks = append(weeks, week)
+	}
+
+	return weeks
+}
+
+// DailyTotal is one day's energy production
+type DailyTotal struct {
+	Date      string  `json:"date"`
+	EnergyKWh float64 `json:"energy_kwh"`
+}
+
+// DailyTotalsFromEnergy converts a daily energy series to DailyTotals
+func DailyTotalsFromEnergy(energy *growatt.EnergyData) []DailyTotal {
+	totals := make([]DailyTotal, len(energy.Datas))
+	for i, d := range energy.Datas {
+		totals[i] = DailyTotal{Date: d.Date, EnergyKWh: d.Energy}
+	}
+	return totals
+}
+
+// RollingAverage returns the trailing mean of energy over window days for each
+// of totals, which should be consecutive days in date order. The first
+// window-1 entries are NaN because they have too few days before them. It
+// returns nil if window is less than 1.
+func RollingAverage(totals []DailyTotal, window int) []float64 {
+	if window < 1 {
+		return nil
+	}
+
+	result := make([]float64, len(totals))
+	var sum float64
+	for i, t := range totals {
+		sum += t.EnergyKWh
+		if i >= window {
+			sum -= totals[i-window].EnergyKWh
+		}
+		if i < window-1 {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = sum / float64(window)
+	}
+
+	return result
+}
@@ -1,7 +1,13 @@
 package stats
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 
@@ -174,6 +180,10 @@ func TestAggregateToHourly(t *testing.T) {
 		t.Errorf("expected date %q, got %q", "2025-02-03", stats.Date)
 	}
 
+	if stats.IntervalMinutes != 5 {
+		t.Errorf("expected interval 5 minutes, got %d", stats.IntervalMinutes)
+	}
+
 	// Check hour 6
 	h6 := stats.Hours[6]
 	if h6.Samples != 4 {
@@ -347,4 +357,1154 @@ func TestGetHourlyRows(t *testing.T) {
 	if hour6Row.Max != 200 {
 		t.Errorf("expected max 200 for hour 6, got %f", hour6Row.Max)
 	}
+
+	// 150 W avg for one hour = 0.150 kWh, matching the daily totals
+	if math.Abs(hour6Row.Energy-0.150) > 0.0001 {
+		t.Errorf("expected energy 0.150 kWh for hour 6, got %f", hour6Row.Energy)
+	}
+}
+
+func TestDetectIntervalMinutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []growatt.ParsedPowerData
+		expected int
+	}{
+		{
+			name:     "empty",
+			data:     nil,
+			expected: DefaultIntervalMinutes,
+		},
+		{
+			name:     "single reading",
+			data:     []growatt.ParsedPowerData{{Hour: 12, Minute: 0}},
+			expected: DefaultIntervalMinutes,
+		},
+		{
+			name: "five minute",
+			data: []growatt.ParsedPowerData{
+				{Hour: 6, Minute: 0}, {Hour: 6, Minute: 5}, {Hour: 6, Minute: 10},
+			},
+			expected: 5,
+		},
+		{
+			name: "unsorted with gaps",
+			data: []growatt.ParsedPowerData{
+				{Hour: 7, Minute: 0}, {Hour: 6, Minute: 0}, {Hour: 6, Minute: 15}, {Hour: 6, Minute: 30},
+			},
+			expected: 15,
+		},
+		{
+			name: "duplicate times ignored",
+			data: []growatt.ParsedPowerData{
+				{Hour: 6, Minute: 0}, {Hour: 6, Minute: 0}, {Hour: 6, Minute: 10},
+			},
+			expected: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectIntervalMinutes(tt.data)
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetectClipping(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+
+	// Synthetic clear day capped at 5000 W from 11:00 to 11:20 and at 13:00
+	var data []growatt.ParsedPowerData
+	add := func(hour, minute int, power float64) {
+		data = append(data, growatt.ParsedPowerData{
+			Date: date, Time: fmt.Sprintf("%02d:%02d", hour, minute), Power: power, Hour: hour, Minute: minute,
+		})
+	}
+	add(10, 50, 4700)
+	add(10, 55, 4900)
+	add(11, 0, 5000)
+	add(11, 5, 4995)
+	add(11, 10, 5000)
+	add(11, 15, 5000)
+	add(11, 20, 4960)
+	add(11, 25, 4800)
+	add(13, 0, 5000)
+	add(12, 55, 4850) // out of order
+
+	result := DetectClipping(data, 5000)
+
+	if result.SaturatedSamples != 6 {
+		t.Errorf("expected 6 saturated samples, got %d", result.SaturatedSamples)
+	}
+
+	if result.Duration != 30*time.Minute {
+		t.Errorf("expected 30m duration, got %v", result.Duration)
+	}
+
+	if len(result.Intervals) != 2 {
+		t.Fatalf("expected 2 intervals, got %d: %+v", len(result.Intervals), result.Intervals)
+	}
+
+	first := result.Intervals[0]
+	if first.Start != "11:00" || first.End != "11:20" || first.Samples != 5 {
+		t.Errorf("unexpected first interval: %+v", first)
+	}
+
+	second := result.Intervals[1]
+	if second.Start != "13:00" || second.End != "13:00" || second.Samples != 1 {
+		t.Errorf("unexpected second interval: %+v", second)
+	}
+}
+
+func TestDetectClippingNone(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+	data := []growatt.ParsedPowerData{
+		{Date: date, Time: "12:00", Power: 3000, Hour: 12, Minute: 0},
+		{Date: date, Time: "12:05", Power: 3100, Hour: 12, Minute: 5},
+	}
+
+	result := DetectClipping(data, 5000)
+	if result.SaturatedSamples != 0 || len(result.Intervals) != 0 || result.Duration != 0 {
+		t.Errorf("expected no clipping, got %+v", result)
+	}
+
+	result = DetectClipping(nil, 5000)
+	if result.SaturatedSamples != 0 {
+		t.Errorf("expected no clipping for empty input, got %+v", result)
+	}
+
+	result = DetectClipping(data, 0)
+	if result.SaturatedSamples != 0 {
+		t.Errorf("expected no clipping for zero cap, got %+v", result)
+	}
+}
+
+func TestAggregateStringsHourly(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "min_history.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var resp growatt.Response[growatt.MINHistoryResponse]
+	if err := json.Unmarshal(fixture, &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	result := AggregateStringsHourly(resp.Data.Datas)
+
+	// The malformed time is skipped, leaving hours 6 and 12
+	if len(result) != 2 {
+		t.Fatalf("expected 2 hours, got %d: %+v", len(result), result)
+	}
+
+	tests := []struct {
+		hour    int
+		string1 float64
+		string2 float64
+		samples int
+	}{
+		{6, 120.0 * 0.1, 118.2 * 0.1, 1},
+		{12, (380.1*6.1 + 381.0*6.2) / 2, (375.4*6.0 + 376.0*6.1) / 2, 2},
+	}
+
+	for i, tt := range tests {
+		h := result[i]
+		if h.Hour != tt.hour || h.Samples != tt.samples {
+			t.Errorf("row %d: expected hour %d with %d samples, got hour %d with %d", i, tt.hour, tt.samples, h.Hour, h.Samples)
+		}
+		if math.Abs(h.String1-tt.string1) > 1e-9 {
+			t.Errorf("hour %d: expected string 1 %f W, got %f", tt.hour, tt.string1, h.String1)
+		}
+		if math.Abs(h.String2-tt.string2) > 1e-9 {
+			t.Errorf("hour %d: expected string 2 %f W, got %f", tt.hour, tt.string2, h.String2)
+		}
+	}
+}
+
+func TestSunHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		energy   float64
+		peakKW   float64
+		expected float64
+	}{
+		{"typical", 32.5, 6.5, 5},
+		{"zero energy", 0, 6.5, 0},
+		{"zero peak", 32.5, 0, 0},
+		{"negative peak", 32.5, -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SunHours(tt.energy, tt.peakKW); got != tt.expected {
+				t.Errorf("expected %f, got %f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDailyStatsSunHours(t *testing.T) {
+	// 12 five-minute samples at 4000 W = 4 kWh
+	ds := &DailyStats{IntervalMinutes: 5}
+	for i := range ds.Hours {
+		ds.Hours[i] = NewHourlyStats(i)
+	}
+	for i := 0; i < 12; i++ {
+		ds.Hours[12].AddValue(4000)
+	}
+	ds.Hours[12].Finalize()
+
+	if math.Abs(ds.EnergyKWh()-4) > 1e-9 {
+		t.Errorf("expected 4 kWh, got %f", ds.EnergyKWh())
+	}
+	if math.Abs(ds.SunHours(8)-0.5) > 1e-9 {
+		t.Errorf("expected 0.5 sun hours, got %f", ds.SunHours(8))
+	}
+
+	multi := &MultiDayStats{DailyAverage: 30}
+	if multi.SunHours(6) != 5 {
+		t.Errorf("expected 5 sun hours, got %f", multi.SunHours(6))
+	}
+	if multi.SunHours(0) != 0 {
+		t.Errorf("expected 0 sun hours without peak power, got %f", multi.SunHours(0))
+	}
+}
+
+func TestEstimateThermalLoss(t *testing.T) {
+	// Synthetic hot midday: 5-minute readings at 5000 W, 60 C from 12:00 to 12:55
+	var points []growatt.MINHistoryDataPoint
+	for h := 10; h < 15; h++ {
+		for m := 0; m < 60; m += 5 {
+			temp := 35.0
+			if h == 12 {
+				temp = 60
+			}
+			points = append(points, growatt.MINHistoryDataPoint{
+				Time:        fmt.Sprintf("2025-07-01 %02d:%02d:00", h, m),
+				Pac:         5000,
+				Temperature: growatt.FlexFloat(temp),
+			})
+		}
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		coeff     float64
+		expected  float64
+	}{
+		// 12 readings x 5000 W x (10 C x 0.5%) x 5 min = 0.25 kWh
+		{"hot hour above threshold", 50, 0.005, 0.25},
+		{"all readings below threshold", 70, 0.005, 0},
+		{"zero coefficient", 50, 0, 0},
+		// Loss is capped at the full output: 60 readings x 5000 W x 5 min = 25 kWh
+		{"capped at full output", 0, 1, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateThermalLoss(points, tt.threshold, tt.coeff)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %f kWh, got %f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAggregateToHourly_EndOfDay(t *testing.T) {
+	parsed, err := growatt.ParsePowerData(&growatt.PowerData{
+		Date: "2025-02-03",
+		Powers: []growatt.PowerDataPoint{
+			{Time: "23:50", Power: 30},
+			{Time: "23:55", Power: 20},
+			{Time: "24:00", Power: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ds := AggregateToHourly(parsed)
+
+	if ds.Hours[23].Samples != 3 {
+		t.Errorf("expected the 24:00 reading in hour 23, got %d samples", ds.Hours[23].Samples)
+	}
+	if ds.Hours[23].Mean != 20 {
+		t.Errorf("expected mean 20, got %f", ds.Hours[23].Mean)
+	}
+	if ds.IntervalMinutes != 5 {
+		t.Errorf("expected 5-minute interval, got %d", ds.IntervalMinutes)
+	}
+}
+
+func TestUseReportedEnergy(t *testing.T) {
+	// Each day has a single hour averaging 3000 W, integrating to 3 kWh
+	newDay := func(date string) *DailyStats {
+		ds := &DailyStats{Date: date, IntervalMinutes: 5}
+		for i := range ds.Hours {
+			ds.Hours[i] = NewHourlyStats(i)
+		}
+		ds.Hours[12].AddValue(3000)
+		ds.Hours[12].Finalize()
+		return ds
+	}
+	days := []*DailyStats{newDay("2025-02-01"), newDay("2025-02-02")}
+
+	tests := []struct {
+		name      string
+		reported  map[string]float64
+		wantUsed  int
+		wantTotal float64
+	}{
+		{"energy available", map[string]float64{"2025-02-01": 10.5, "2025-02-02": 12.5}, 2, 23},
+		{"partial fallback", map[string]float64{"2025-02-02": 12.5}, 1, 15.5},
+		{"full fallback", nil, 0, 6},
+		{"reported zero", map[string]float64{"2025-02-01": 0, "2025-02-02": 0}, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := AggregateDays(days)
+			if math.Abs(m.TotalProduction-6) > 1e-9 {
+				t.Fatalf("expected integrated total 6 kWh, got %f", m.TotalProduction)
+			}
+
+			used := m.UseReportedEnergy(days, tt.reported)
+			if used != tt.wantUsed {
+				t.Errorf("expected %d reported days, got %d", tt.wantUsed, used)
+			}
+			if math.Abs(m.TotalProduction-tt.wantTotal) > 1e-9 {
+				t.Errorf("expected total %f kWh, got %f", tt.wantTotal, m.TotalProduction)
+			}
+			if math.Abs(m.DailyAverage-tt.wantTotal/2) > 1e-9 {
+				t.Errorf("expected daily average %f kWh, got %f", tt.wantTotal/2, m.DailyAverage)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		expected float64
+	}{
+		{33.49999999998, 1, 33.5},
+		{33.49999999998, 3, 33.5},
+		{4500.25, 1, 4500.3},
+		{0.0254, 3, 0.025},
+		{0.0255, 3, 0.026},
+		{-12.345, 1, -12.3},
+		{7.6, 0, 8},
+	}
+
+	for _, tt := range tests {
+		if got := Round(tt.value, tt.decimals); got != tt.expected {
+			t.Errorf("Round(%v, %d) = %v, want %v", tt.value, tt.decimals, got, tt.expected)
+		}
+	}
+}
+
+func TestTypicalDay(t *testing.T) {
+	// newDay builds a day with samples per hour at the given power
+	newDay := func(date string, interval int, hours map[int][]float64) *DailyStats {
+		ds := &DailyStats{Date: date, IntervalMinutes: interval}
+		for i := range ds.Hours {
+			ds.Hours[i] = NewHourlyStats(i)
+		}
+		for hour, values := range hours {
+			for _, v := range values {
+				ds.Hours[hour].AddValue(v)
+			}
+		}
+		for i := range ds.Hours {
+			ds.Hours[i].Finalize()
+		}
+		return ds
+	}
+
+	full := func(power float64) []float64 {
+		values := make([]float64, 12)
+		for i := range values {
+			values[i] = power
+		}
+		return values
+	}
+
+	days := []*DailyStats{
+		newDay("2025-02-01", 5, map[int][]float64{11: full(2000), 12: full(3000)}),
+		newDay("2025-02-02", 5, map[int][]float64{11: full(4000), 12: full(5000)}),
+		// Partial coverage: no data for hour 11, half an hour of data at 12
+		newDay("2025-02-03", 5, map[int][]float64{12: full(4000)[:6]}),
+	}
+
+	typical := TypicalDay(days)
+	if typical == nil {
+		t.Fatal("expected a typical day")
+	}
+	if typical.Date != TypicalDayLabel || typical.IntervalMinutes != 5 {
+		t.Errorf("unexpected typical day header: %s, %d min", typical.Date, typical.IntervalMinutes)
+	}
+
+	tests := []struct {
+		hour    int
+		mean    float64
+		min     float64
+		max     float64
+		samples int
+	}{
+		{11, 3000, 2000, 4000, 12}, // averaged over the two days that cover it
+		{12, 4000, 3000, 5000, 10}, // (12 + 12 + 6) / 3 samples
+		{13, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		h := typical.Hours[tt.hour]
+		if h.Mean != tt.mean || h.Min != tt.min || h.Max != tt.max || h.Samples != tt.samples {
+			t.Errorf("hour %d: expected mean %.0f min %.0f max %.0f samples %d, got mean %.0f min %.0f max %.0f samples %d",
+				tt.hour, tt.mean, tt.min, tt.max, tt.samples, h.Mean, h.Min, h.Max, h.Samples)
+		}
+	}
+
+	// 3000 W + 4000 W, one hour each
+	if math.Abs(typical.EnergyKWh()-7) > 1e-9 {
+		t.Errorf("unexpected typical energy: %f kWh", typical.EnergyKWh())
+	}
+
+	if TypicalDay(nil) != nil {
+		t.Error("expected nil for no days")
+	}
+}
+
+func TestEnergyEstimatesAgree(t *testing.T) {
+	day := &DailyStats{Date: "2025-02-03", IntervalMinutes: 5}
+	for i := 0; i < 24; i++ {
+		day.Hours[i] = NewHourlyStats(i)
+	}
+	day.Hours[10].AddValue(1000)
+	day.Hours[11].AddValue(3000)
+	day.Hours[11].AddValue(5000)
+	for i := 0; i < 24; i++ {
+		day.Hours[i].Finalize()
+	}
+
+	var rowTotal float64
+	for _, row := range GetHourlyRows([]*DailyStats{day}) {
+		rowTotal += row.Energy
+	}
+	multi := AggregateDays([]*DailyStats{day})
+	_, _, bestKWh, _ := BestWorstDays([]*DailyStats{day})
+
+	for name, got := range map[string]float64{
+		"EnergyKWh":       day.EnergyKWh(),
+		"hourly rows":     rowTotal,
+		"TotalProduction": multi.TotalProduction,
+		"BestWorstDays":   bestKWh,
+	} {
+		if math.Abs(got-5) > 1e-9 {
+			t.Errorf("%s: expected 5 kWh, got %f", name, got)
+		}
+	}
+
+	if sun := day.SunHours(2.5); math.Abs(sun-2) > 1e-9 {
+		t.Errorf("expected 2 sun hours, got %f", sun)
+	}
+}
+
+func TestDailyEnergyKWhMatchesPipeline(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "growatt", "testdata", "plant_power.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	var resp growatt.Response[growatt.PowerDataRaw]
+	if err := json.Unmarshal(fixture, &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	data := &growatt.PowerData{Date: "2025-02-03"}
+	for tm, power := range resp.Data.Powers {
+		data.Powers = append(data.Powers, growatt.PowerDataPoint{Time: tm, Power: power})
+	}
+
+	parsed, err := growatt.ParsePowerData(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sum float64
+	for _, r := range parsed {
+		sum += r.Power
+	}
+	expected := sum * 5 / 60 / 1000
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		expected float64
+	}{
+		{"detected interval", 0, expected},
+		{"explicit interval", 5 * time.Minute, expected},
+		{"longer interval", 10 * time.Minute, expected * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := growatt.DailyEnergyKWh(data, tt.interval)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("expected %f kWh, got %f", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := growatt.DailyEnergyKWh(&growatt.PowerData{Date: "bogus"}, 0); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
+
+func TestGetHourlyRows_Coverage(t *testing.T) {
+	day := &DailyStats{Date: "2025-02-03", IntervalMinutes: 5}
+	for i := 0; i < 24; i++ {
+		day.Hours[i] = NewHourlyStats(i)
+	}
+	for i := 0; i < 12; i++ {
+		day.Hours[11].AddValue(3000)
+	}
+	for i := 0; i < 10; i++ {
+		day.Hours[12].AddValue(3000)
+	}
+	// An end-of-day 24:00 reading gives hour 23 one sample more than expected
+	for i := 0; i < 13; i++ {
+		day.Hours[23].AddValue(0)
+	}
+	for i := 0; i < 24; i++ {
+		day.Hours[i].Finalize()
+	}
+
+	rows := GetHourlyRows([]*DailyStats{day})
+
+	tests := []struct {
+		name     string
+		hour     int
+		expected float64
+	}{
+		{"full", 11, 100},
+		{"partial", 12, 1000.0 / 12},
+		{"empty", 13, 0},
+		{"capped", 23, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rows[tt.hour].CoveragePct; math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("hour %d: expected coverage %f, got %f", tt.hour, tt.expected, got)
+			}
+		})
+	}
+
+	// Without a detected interval the default 5 minutes is assumed
+	day.IntervalMinutes = 0
+	if got := GetHourlyRows([]*DailyStats{day})[12].CoveragePct; math.Abs(got-1000.0/12) > 1e-9 {
+		t.Errorf("expected default-interval coverage %f, got %f", 1000.0/12, got)
+	}
+}
+
+// benchmarkDays builds n days of 5-minute samples over daylight hours
+func benchmarkDays(n int) []*DailyStats {
+	days := make([]*DailyStats, n)
+	for d := range days {
+		ds := &DailyStats{Date: fmt.Sprintf("day-%04d", d), IntervalMinutes: 5}
+		for hour := 0; hour < 24; hour++ {
+			ds.Hours[hour] = NewHourlyStats(hour)
+			if hour >= 6 && hour < 20 {
+				for i := 0; i < 12; i++ {
+					ds.Hours[hour].AddValue(float64((d*7+hour*13+i*3)%5000) + 0.5)
+				}
+			}
+			ds.Hours[hour].Finalize()
+		}
+		days[d] = ds
+	}
+	return days
+}
+
+func BenchmarkAggregateDays(b *testing.B) {
+	for _, n := range []int{30, 1000} {
+		days := benchmarkDays(n)
+		b.Run(fmt.Sprintf("days=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				AggregateDays(days)
+			}
+		})
+	}
+}
+
+func TestAggregateDays_LargeRangeMatchesReference(t *testing.T) {
+	days := benchmarkDays(200)
+	result := AggregateDays(days)
+
+	for hour := 0; hour < 24; hour++ {
+		agg := result.ByHour[hour]
+		if len(agg.Values) != agg.SampleDays {
+			t.Fatalf("hour %d: %d values for %d sample days", hour, len(agg.Values), agg.SampleDays)
+		}
+		if agg.SampleDays == 0 {
+			continue
+		}
+		for i, day := range days {
+			if agg.Values[i] != day.Hours[hour].Mean {
+				t.Fatalf("hour %d: values out of day order at %d", hour, i)
+			}
+		}
+		if agg.Median != CalculateMedian(agg.Values) {
+			t.Errorf("hour %d: median %f differs from CalculateMedian %f", hour, agg.Median, CalculateMedian(agg.Values))
+		}
+		if agg.StdDev != CalculateStdDev(agg.Values, agg.Average) {
+			t.Errorf("hour %d: unexpected std dev %f", hour, agg.StdDev)
+		}
+	}
+}
+
+func TestAggregator_MatchesAggregateDays(t *testing.T) {
+	days := benchmarkDays(60)
+	// Leave some hours uncovered on some days
+	days[3].Hours[12] = nil
+	days[7].Hours[6] = NewHourlyStats(6)
+
+	batch := AggregateDays(days)
+
+	agg := NewAggregator()
+	for _, day := range days {
+		agg.AddDay(day)
+	}
+	streamed := agg.Result()
+
+	if !reflect.DeepEqual(streamed, batch) {
+		t.Errorf("streaming result differs from batch result:\nstreamed: %+v\nbatch:    %+v", streamed, batch)
+	}
+}
+
+func TestAggregator_Empty(t *testing.T) {
+	if result := NewAggregator().Result(); result != nil {
+		t.Errorf("expected nil result with no days, got %+v", result)
+	}
+}
+
+func TestDiffCumulative(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected []float64
+	}{
+		{
+			name:     "empty",
+			values:   nil,
+			expected: nil,
+		},
+		{
+			name:     "single value",
+			values:   []float64{100},
+			expected: nil,
+		},
+		{
+			name:     "monotonic",
+			values:   []float64{100, 101.5, 101.5, 104},
+			expected: []float64{1.5, 0, 2.5},
+		},
+		{
+			name:     "reset mid-series",
+			values:   []float64{5000, 5002, 5005, 3, 6, 10},
+			expected: []float64{2, 3, 0, 3, 4},
+		},
+		{
+			name:     "reset to zero",
+			values:   []float64{250, 251, 0, 0.5},
+			expected: []float64{1, 0, 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffCumulative(tt.values)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d diffs, got %d (%v)", len(tt.expected), len(got), got)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.expected[i]) > 1e-9 {
+					t.Errorf("diff %d: expected %v, got %v", i, tt.expected[i], got[i])
+				}
+				if got[i] < 0 {
+					t.Errorf("diff %d is negative: %v", i, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHourlyKWhSeries(t *testing.T) {
+	day1 := &DailyStats{Date: "2025-02-01"}
+	day2 := &DailyStats{Date: "2025-02-02"}
+
+	// Hour 10 on both days: means 1000 W and 3000 W -> (1.0 + 3.0) / 2 kWh
+	day1.Hours[10] = &HourlyStats{Hour: 10, Samples: 12, Mean: 1000}
+	day2.Hours[10] = &HourlyStats{Hour: 10, Samples: 12, Mean: 3000}
+	// Hour 12 only on day 1: averaged over the one day that has it
+	day1.Hours[12] = &HourlyStats{Hour: 12, Samples: 6, Mean: 2500}
+	// Hour 14 present but without samples is ignored
+	day2.Hours[14] = &HourlyStats{Hour: 14}
+
+	var expected [24]float64
+	expected[10] = 2.0
+	expected[12] = 2.5
+
+	got := HourlyKWhSeries([]*DailyStats{day1, day2})
+	for hour := 0; hour < 24; hour++ {
+		if math.Abs(got[hour]-expected[hour]) > 1e-9 {
+			t.Errorf("hour %d: expected %.3f kWh, got %.3f", hour, expected[hour], got[hour])
+		}
+	}
+
+	if empty := HourlyKWhSeries(nil); empty != [24]float64{} {
+		t.Errorf("expected zero series for no days, got %v", empty)
+	}
+}
+
+func TestAggregateDays_ExcludesEmptyDaysFromAverage(t *testing.T) {
+	day := func(date string, meanW float64) *DailyStats {
+		d := &DailyStats{Date: date}
+		for i := 0; i < 24; i++ {
+			d.Hours[i] = NewHourlyStats(i)
+		}
+		if meanW > 0 {
+			d.Hours[12] = &HourlyStats{Hour: 12, Samples: 12, Mean: meanW, Min: meanW, Max: meanW}
+		}
+		return d
+	}
+
+	// The middle day has no readings at all, as when the API has not populated it
+	days := []*DailyStats{
+		day("2025-02-01", 4000),
+		day("2025-02-02", 0),
+		day("2025-02-03", 2000),
+	}
+
+	result := AggregateDays(days)
+	if result.DaysAnalyzed != 3 {
+		t.Errorf("expected 3 days analyzed, got %d", result.DaysAnalyzed)
+	}
+	if result.DaysWithData != 2 {
+		t.Errorf("expected 2 days with data, got %d", result.DaysWithData)
+	}
+	if math.Abs(result.TotalProduction-6.0) > 1e-9 {
+		t.Errorf("expected total 6.0 kWh, got %.3f", result.TotalProduction)
+	}
+	if math.Abs(result.DailyAverage-3.0) > 1e-9 {
+		t.Errorf("expected daily average 3.0 kWh over days with data, got %.3f", result.DailyAverage)
+	}
+
+	// Reported energy for the empty day counts it again
+	result.UseReportedEnergy(days, map[string]float64{"2025-02-02": 0.3})
+	if math.Abs(result.DailyAverage-6.3/3) > 1e-9 {
+		t.Errorf("expected daily average %.3f with reported energy, got %.3f", 6.3/3, result.DailyAverage)
+	}
+}
+
+func TestMultiDayStats_PeakPowerMetrics(t *testing.T) {
+	m := &MultiDayStats{DaysAnalyzed: 30, TotalProduction: 720, DailyAverage: 24}
+
+	tests := []struct {
+		peakKW        float64
+		specificYield float64
+		capacity      float64
+	}{
+		{6, 120, 24.0 / 144},
+		{0, 0, 0},
+		{-1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := m.SpecificYield(tt.peakKW); math.Abs(got-tt.specificYield) > 1e-9 {
+			t.Errorf("SpecificYield(%v): expected %v, got %v", tt.peakKW, tt.specificYield, got)
+		}
+		if got := m.CapacityFactor(tt.peakKW); math.Abs(got-tt.capacity) > 1e-9 {
+			t.Errorf("CapacityFactor(%v): expected %v, got %v", tt.peakKW, tt.capacity, got)
+		}
+	}
+}
+
+func TestStatsJSONKeys(t *testing.T) {
+	keysOf := func(t *testing.T, v any) map[string]json.RawMessage {
+		t.Helper()
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", v, err)
+		}
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(data, &keys); err != nil {
+			t.Fatalf("unmarshal %T: %v", v, err)
+		}
+		return keys
+	}
+	expectKeys := func(t *testing.T, v any, expected ...string) {
+		t.Helper()
+		keys := keysOf(t, v)
+		for _, key := range expected {
+			if _, ok := keys[key]; !ok {
+				t.Errorf("%T: missing key %q in %v", v, key, keys)
+			}
+		}
+		for key := range keys {
+			if !slices.Contains(expected, key) {
+				t.Errorf("%T: unexpected key %q", v, key)
+			}
+		}
+	}
+
+	days := benchmarkDays(3)
+	multi := AggregateDays(days)
+
+	expectKeys(t, multi, "start_date", "end_date", "days_analyzed", "days_with_data", "by_hour",
+		"total_production_kwh", "daily_average_kwh", "peak_hour", "peak_power_avg_watts")
+	expectKeys(t, multi.ByHour[12], "hour", "sample_days", "min_watts", "max_watts", "average_watts",
+		"median_watts", "std_dev_watts", "values")
+	expectKeys(t, days[0], "date", "interval_minutes", "hours")
+	expectKeys(t, days[0].Hours[12], "hour", "samples", "min_watts", "max_watts", "sum_watts", "mean_watts",
+		"std_dev_watts", "values")
+	expectKeys(t, HourlyRow{}, "date", "hour", "min_watts", "max_watts", "avg_watts", "samples", "energy_kwh",
+		"coverage_pct")
+
+	// Values is omitted when empty
+	if _, ok := keysOf(t, &HourlyStats{})["values"]; ok {
+		t.Error("expected empty values to be omitted")
+	}
+}
+
+func TestBestWorstDays(t *testing.T) {
+	day := func(date string, means ...float64) *DailyStats {
+		d := &DailyStats{Date: date}
+		for i := 0; i < 24; i++ {
+			d.Hours[i] = NewHourlyStats(i)
+		}
+		for i, mean := range means {
+			d.Hours[10+i] = &HourlyStats{Hour: 10 + i, Samples: 12, Mean: mean}
+		}
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		days     []*DailyStats
+		best     string
+		worst    string
+		bestKWh  float64
+		worstKWh float64
+	}{
+		{
+			name:     "distinct totals",
+			days:     []*DailyStats{day("2025-02-01", 3000, 2000), day("2025-02-02", 500), day("2025-02-03", 4000, 4000)},
+			best:     "2025-02-03",
+			worst:    "2025-02-02",
+			bestKWh:  8,
+			worstKWh: 0.5,
+		},
+		{
+			name:     "ties go to the earliest date",
+			days:     []*DailyStats{day("2025-02-01", 1000), day("2025-02-02", 3000), day("2025-02-03", 1000), day("2025-02-04", 3000)},
+			best:     "2025-02-02",
+			worst:    "2025-02-01",
+			bestKWh:  3,
+			worstKWh: 1,
+		},
+		{
+			name:     "days without readings are skipped",
+			days:     []*DailyStats{day("2025-02-01"), day("2025-02-02", 2500), day("2025-02-03", 1500)},
+			best:     "2025-02-02",
+			worst:    "2025-02-03",
+			bestKWh:  2.5,
+			worstKWh: 1.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, worst, bestKWh, worstKWh := BestWorstDays(tt.days)
+			if best == nil || worst == nil {
+				t.Fatal("expected best and worst days")
+			}
+			if best.Date != tt.best || math.Abs(bestKWh-tt.bestKWh) > 1e-9 {
+				t.Errorf("best: expected %s (%.2f kWh), got %s (%.2f kWh)", tt.best, tt.bestKWh, best.Date, bestKWh)
+			}
+			if worst.Date != tt.worst || math.Abs(worstKWh-tt.worstKWh) > 1e-9 {
+				t.Errorf("worst: expected %s (%.2f kWh), got %s (%.2f kWh)", tt.worst, tt.worstKWh, worst.Date, worstKWh)
+			}
+		})
+	}
+
+	if best, worst, _, _ := BestWorstDays(nil); best != nil || worst != nil {
+		t.Error("expected nil days for empty input")
+	}
+}
+
+func TestProductionHeatmap(t *testing.T) {
+	energy := &growatt.EnergyData{Datas: []growatt.EnergyDataPoint{
+		{Date: "2025-02-01", Energy: 12.5},
+		{Date: "2025-02-02", Energy: 0},
+		{Date: "2025-02-03", Energy: 20.25},
+	}}
+
+	heatmap := ProductionHeatmap(energy)
+	if len(heatmap) != 3 {
+		t.Fatalf("expected 3 dates, got %d", len(heatmap))
+	}
+	if heatmap["2025-02-01"] != 12.5 || heatmap["2025-02-03"] != 20.25 {
+		t.Errorf("unexpected heatmap: %v", heatmap)
+	}
+	if v, ok := heatmap["2025-02-02"]; !ok || v != 0 {
+		t.Errorf("expected a zero day to be kept, got %v, %v", v, ok)
+	}
+}
+
+func TestHeatmapWeeks_Month(t *testing.T) {
+	// February 2025 starts on a Saturday and ends on a Friday
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	weeks := HeatmapWeeks(from, to)
+	if len(weeks) != 5 {
+		t.Fatalf("expected 5 weeks, got %d", len(weeks))
+	}
+
+	starts := []string{"2025-01-26", "2025-02-02", "2025-02-09", "2025-02-16", "2025-02-23"}
+	for i, w := range weeks {
+		if got := w.Start.Format("2006-01-02"); got != starts[i] {
+			t.Errorf("week %d: expected start %s, got %s", i, starts[i], got)
+		}
+		if w.Start.Weekday() != time.Sunday {
+			t.Errorf("week %d starts on %s", i, w.Start.Weekday())
+		}
+	}
+
+	if weeks[0].Days != [7]string{"", "", "", "", "", "", "2025-02-01"} {
+		t.Errorf("first week: got %q", weeks[0].Days)
+	}
+	if weeks[1].Days[0] != "2025-02-02" || weeks[1].Days[6] != "2025-02-08" {
+		t.Errorf("second week: got %q", weeks[1].Days)
+	}
+	if weeks[4].Days != [7]string{"2025-02-23", "2025-02-24", "2025-02-25", "2025-02-26", "2025-02-27", "2025-02-28", ""} {
+		t.Errorf("last week: got %q", weeks[4].Days)
+	}
+
+	total := 0
+	for _, w := range weeks {
+		for _, d := range w.Days {
+			if d != "" {
+				total++
+			}
+		}
+	}
+	if total != 28 {
+		t.Errorf("expected 28 days in the grid, got %d", total)
+	}
+}
+
+func TestHeatmapWeeks_SingleDay(t *testing.T) {
+	day := time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+	weeks := HeatmapWeeks(day, day)
+	if len(weeks) != 1 || weeks[0].Days[3] != "2025-02-05" {
+		t.Errorf("unexpected weeks: %+v", weeks)
+	}
+}
+
+func TestRollingAverage(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50, 60, 70, 80, 90}
+	totals := make([]DailyTotal, len(values))
+	for i, v := range values {
+		totals[i] = DailyTotal{Date: fmt.Sprintf("2025-02-%02d", i+1), EnergyKWh: v}
+	}
+
+	nan := math.NaN()
+	tests := []struct {
+		window   int
+		expected []float64
+	}{
+		{3, []float64{nan, nan, 20, 30, 40, 50, 60, 70, 80}},
+		{7, []float64{nan, nan, nan, nan, nan, nan, 40, 50, 60}},
+		{1, values},
+		{10, []float64{nan, nan, nan, nan, nan, nan, nan, nan, nan}},
+	}
+
+	for _, tt := range tests {
+		got := RollingAverage(totals, tt.window)
+		if len(got) != len(tt.expected) {
+			t.Fatalf("window %d: expected %d values, got %d", tt.window, len(tt.expected), len(got))
+		}
+		for i, want := range tt.expected {
+			if math.IsNaN(want) {
+				if !math.IsNaN(got[i]) {
+					t.Errorf("window %d, day %d: expected NaN, got %v", tt.window, i, got[i])
+				}
+				continue
+			}
+			if math.Abs(got[i]-want) > 1e-9 {
+				t.Errorf("window %d, day %d: expected %v, got %v", tt.window, i, want, got[i])
+			}
+		}
+	}
+
+	if RollingAverage(totals, 0) != nil {
+		t.Error("expected nil for a zero window")
+	}
+}
+
+func TestDailyTotalsFromEnergy(t *testing.T) {
+	energy := &growatt.EnergyData{Datas: []growatt.EnergyDataPoint{
+		{Date: "2025-01-01", Energy: 28.5},
+		{Date: "2025-01-02", Energy: 32.1},
+	}}
+
+	totals := DailyTotalsFromEnergy(energy)
+	expected := []DailyTotal{{"2025-01-01", 28.5}, {"2025-01-02", 32.1}}
+	if !reflect.DeepEqual(totals, expected) {
+		t.Errorf("expected %v, got %v", expected, totals)
+	}
+}
+
+func TestAggregateToHourly_MinSamples(t *testing.T) {
+	date := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+	data := []growatt.ParsedPowerData{
+		// A single stray reading at dawn
+		{Date: date, Hour: 6, Minute: 55, Power: 600},
+	}
+	for m := 0; m < 60; m += 5 {
+		data = append(data, growatt.ParsedPowerData{Date: date, Hour: 12, Minute: m, Power: 2000})
+	}
+
+	// Default: every hour with samples counts
+	day := AggregateToHourly(data)
+	if day.Hours[6].Excluded {
+		t.Error("expected hour 6 to count without a threshold")
+	}
+	if got := integratedEnergyKWh(day, 0); math.Abs(got-2.6) > 1e-9 {
+		t.Errorf("expected 2.6 kWh without a threshold, got %v", got)
+	}
+
+	day = AggregateToHourly(data, WithMinSamples(3))
+	h := day.Hours[6]
+	if !h.Excluded {
+		t.Fatal("expected hour 6 with 1 sample to be excluded")
+	}
+	if h.Samples != 1 || h.Mean != 600 {
+		t.Errorf("expected excluded hour to keep its sample count and mean, got %d samples, mean %v", h.Samples, h.Mean)
+	}
+	if day.Hours[12].Excluded {
+		t.Error("expected hour 12 with 12 samples to count")
+	}
+	if day.Hours[0].Excluded {
+		t.Error("expected an hour without samples not to be marked excluded")
+	}
+	if got := day.EnergyKWh(); math.Abs(got-2.0) > 1e-9 {
+		t.Errorf("expected 2.0 kWh from hour 12 only, got %v", got)
+	}
+
+	multi := AggregateDays([]*DailyStats{day})
+	if multi.ByHour[6].SampleDays != 0 {
+		t.Errorf("expected excluded hour to be left out of averages, got %d sample days", multi.ByHour[6].SampleDays)
+	}
+	if math.Abs(multi.TotalProduction-2.0) > 1e-9 {
+		t.Errorf("expected 2.0 kWh total, got %v", multi.TotalProduction)
+	}
+}
+
+func TestAggregateDays_MinSamples(t *testing.T) {
+	date := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+	data := []growatt.ParsedPowerData{
+		{Date: date, Hour: 6, Minute: 55, Power: 600},
+		{Date: date, Hour: 12, Minute: 0, Power: 2000},
+		{Date: date, Hour: 12, Minute: 5, Power: 2000},
+	}
+
+	// Days aggregated without a threshold can still be filtered across days
+	day := AggregateToHourly(data)
+	multi := AggregateDays([]*DailyStats{day}, WithMinSamples(2))
+	if multi.ByHour[6].SampleDays != 0 || multi.ByHour[12].SampleDays != 1 {
+		t.Errorf("expected only hour 12 to count, got sample days %d and %d", multi.ByHour[6].SampleDays, multi.ByHour[12].SampleDays)
+	}
+	if multi.PeakHour != 12 || math.Abs(multi.TotalProduction-2.0) > 1e-9 {
+		t.Errorf("expected peak hour 12 and 2.0 kWh, got %d and %v", multi.PeakHour, multi.TotalProduction)
+	}
+	if multi.DaysWithData != 1 {
+		t.Errorf("expected the day to still count as having data, got %d", multi.DaysWithData)
+	}
+
+	// The streaming form applies the same threshold
+	a := NewAggregator(WithMinSamples(2))
+	a.AddDay(day)
+	if streamed := a.Result(); streamed.ByHour[6].SampleDays != 0 || streamed.TotalProduction != multi.TotalProduction {
+		t.Errorf("expected the aggregator to match AggregateDays, got %+v", streamed)
+	}
+}
+
+func TestMinSamples_TypicalDayAndHourlySeries(t *testing.T) {
+	date := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+	data := []growatt.ParsedPowerData{
+		{Date: date, Hour: 6, Minute: 55, Power: 600},
+	}
+	for m := 0; m < 60; m += 5 {
+		data = append(data, growatt.ParsedPowerData{Date: date, Hour: 12, Minute: m, Power: 2000})
+	}
+	day := AggregateToHourly(data, WithMinSamples(3))
+
+	series := HourlyKWhSeries([]*DailyStats{day})
+	if series[6] != 0 {
+		t.Errorf("expected excluded hour 6 to be left out of the series, got %v kWh", series[6])
+	}
+	if math.Abs(series[12]-2.0) > 1e-9 {
+		t.Errorf("expected 2.0 kWh at hour 12, got %v", series[12])
+	}
+
+	typical := TypicalDay([]*DailyStats{day})
+	if typical.Hours[6].Samples != 0 {
+		t.Errorf("expected excluded hour 6 to be left out of the typical day, got %d samples", typical.Hours[6].Samples)
+	}
+	if got := typical.EnergyKWh(); math.Abs(got-2.0) > 1e-9 {
+		t.Errorf("expected typical day of 2.0 kWh, got %v", got)
+	}
+}
+
+func TestAggregateDays_PeakHourTieGoesToEarliest(t *testing.T) {
+	newDay := func(date string, values map[int]float64) *DailyStats {
+		day := &DailyStats{Date: date}
+		for i := 0; i < 24; i++ {
+			day.Hours[i] = NewHourlyStats(i)
+			if v, ok := values[i]; ok {
+				day.Hours[i].AddValue(v)
+			}
+			day.Hours[i].Finalize()
+		}
+		return day
+	}
+
+	// Hours 11 and 13 average exactly 3000 W; hour 12 is lower
+	days := []*DailyStats{
+		newDay("2025-02-01", map[int]float64{11: 2500, 12: 2000, 13: 3500}),
+		newDay("2025-02-02", map[int]float64{11: 3500, 12: 2000, 13: 2500}),
+	}
+
+	for _, order := range [][]*DailyStats{days, {days[1], days[0]}} {
+		multiDay := AggregateDays(order)
+		if multiDay.ByHour[11].Average != multiDay.ByHour[13].Average {
+			t.Fatalf("expected equal averages, got %v and %v", multiDay.ByHour[11].Average, multiDay.ByHour[13].Average)
+		}
+		if multiDay.PeakHour != 11 {
+			t.Errorf("expected the earlier hour 11 to win the tie, got %d", multiDay.PeakHour)
+		}
+		if multiDay.PeakPowerAvg != 3000 {
+			t.Errorf("expected peak average 3000, got %v", multiDay.PeakPowerAvg)
+		}
+	}
 }
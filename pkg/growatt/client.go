@@ -1,36 +1,98 @@
 package growatt
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 )
 
 const (
-	DefaultBaseURL     = "https://openapi.growatt.com/v1/"
-	DefaultTimeout     = 30 * time.Second
-	DefaultRateLimit   = 3 * time.Second
-	EnvAPIKey          = "GROWATT_API_KEY"
-	EnvBaseURL         = "GROWATT_BASE_URL"
+	DefaultBaseURL            = "https://openapi.growatt.com/v1/"
+	DefaultTimeout            = 30 * time.Second
+	DefaultRateLimit          = 3 * time.Second
+	DefaultRetryDelay         = 10 * time.Second
+	DefaultRetryBudget        = 10
+	DefaultMINHistoryEndpoint = "device/tlx/tlx_data"
+	DefaultAuthHeader         = "token"
+	EnvAPIKey                 = "GROWATT_API_KEY"
+	EnvBaseURL                = "GROWATT_BASE_URL"
+	EnvAuthHeader             = "GROWATT_AUTH_HEADER"
 )
 
 // Client is the Growatt API client
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
-	rateLimit  time.Duration
-	lastCall   time.Time
+	baseURL     string
+	token       string
+	authHeader  string
+	httpClient  *http.Client
+	rateLimit   time.Duration
+	lastCall    time.Time
+	mu          sync.Mutex
+	maxRetries  int
+	retryDelay  time.Duration
+	retryBudget int
+	metrics     MetricsFunc
+	requestLog  RequestLogFunc
+	warnings    WarningFunc
+	progress    ProgressFunc
+	flights     *flightGroup
+
+	minHistoryEndpoint string
+	duplicateMode      MergeMode
+	strictParsing      bool
+	historyCacheDir    string
+	recordDir          string
+	sortDescending     bool
+	skipEmptyDays      bool
+	insecureSkipVerify bool
+	defaultParams      url.Values
 }
 
+// MetricsFunc receives the endpoint, duration and resulting error of each API request
+type MetricsFunc func(endpoint string, dur time.Duration, err error)
+
+// RequestLogFunc receives the correlation ID carried by the request's context
+// (see WithCorrelationID), the endpoint, duration and resulting error of each
+// API request
+type RequestLogFunc func(correlationID, endpoint string, dur time.Duration, err error)
+
+// WarningFunc receives non-fatal problems the client worked around, such as
+// duplicate plants dropped from a listing
+type WarningFunc func(message string)
+
+// ProgressFunc is called after each day of a range fetch completes
+type ProgressFunc func(done, total int, date time.Time)
+
 // ClientOption is a function that configures the client
 type ClientOption func(*Client)
 
+// WithAuthHeader sets the name of the header carrying the API token, for
+// gateways that expect something other than "token"
+func WithAuthHeader(name string) ClientOption {
+	return func(c *Client) {
+		c.authHeader = name
+	}
+}
+
+// WithDefaultQueryParam adds a query parameter, such as lang=en, to every GET
+// request. Parameters set by the request itself take precedence.
+func WithDefaultQueryParam(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.defaultParams == nil {
+			c.defaultParams = url.Values{}
+		}
+		c.defaultParams.Set(key, value)
+	}
+}
+
 // WithBaseURL sets a custom base URL
 func WithBaseURL(url string) ClientOption {
 	return func(c *Client) {
@@ -38,6 +100,82 @@ func WithBaseURL(url string) ClientOption {
 	}
 }
 
+// WithStrictParsing makes list responses fail with ErrCountMismatch when the
+// reported count differs from the number of items parsed
+func WithStrictParsing() ClientOption {
+	return func(c *Client) {
+		c.strictParsing = true
+	}
+}
+
+// WithHistoryCache stores MIN history for past days as JSON files in dir and
+// serves later requests for those days from disk. Today and days without
+// readings are always refetched.
+func WithHistoryCache(dir string) ClientOption {
+	return func(c *Client) {
+		c.historyCacheDir = dir
+	}
+}
+
+// WithResponseRecorder writes the raw body of every response to
+// dir/<endpoint>.json, with slashes in the endpoint replaced by underscores.
+// It is a development aid for capturing real responses as test fixtures; each
+// request overwrites the previous recording of its endpoint.
+func WithResponseRecorder(dir string) ClientOption {
+	return func(c *Client) {
+		c.recordDir = dir
+	}
+}
+
+// WithSortDescending returns plant power and energy series newest first
+// instead of the default oldest first
+func WithSortDescending() ClientOption {
+	return func(c *Client) {
+		c.sortDescending = true
+	}
+}
+
+// WithSkipEmptyDays makes GetPlantPowerRange omit days the API returned no
+// readings for, such as days it has not populated yet, instead of returning
+// them as empty entries that look like zero production
+func WithSkipEmptyDays() ClientOption {
+	return func(c *Client) {
+		c.skipEmptyDays = true
+	}
+}
+
+// Region identifies a regional Growatt API server
+type Region string
+
+// Regional API servers
+const (
+	RegionEurope       Region = "eu" // Europe and other regions (the default server)
+	RegionNorthAmerica Region = "us"
+	RegionAustralia    Region = "au" // Australia and New Zealand
+	RegionChina        Region = "cn"
+)
+
+var regionBaseURLs = map[Region]string{
+	RegionEurope:       DefaultBaseURL,
+	RegionNorthAmerica: "https://openapi-us.growatt.com/v1/",
+	RegionAustralia:    "https://openapi-au.growatt.com/v1/",
+	RegionChina:        "https://openapi-cn.growatt.com/v1/",
+}
+
+// BaseURL returns the API base URL for the region, or "" if the region is unknown
+func (r Region) BaseURL() string {
+	return regionBaseURLs[r]
+}
+
+// WithRegion sets the base URL of a regional server; unknown regions are ignored
+func WithRegion(r Region) ClientOption {
+	return func(c *Client) {
+		if url := r.BaseURL(); url != "" {
+			c.baseURL = url
+		}
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client
 func WithHTTPClient(client *http.Client) ClientOption {
 	return func(c *Client) {
@@ -52,6 +190,40 @@ func WithTimeout(d time.Duration) ClientOption {
 	}
 }
 
+// WithInsecureSkipVerify disables TLS certificate verification while keeping
+// the configured timeout. Intended for testing against self-hosted gateways
+// with self-signed certificates only; never use it against the real API.
+// It applies to a copy of the HTTP client, so a client passed to
+// WithHTTPClient is left unchanged, and it is reported through WithWarnings.
+func WithInsecureSkipVerify() ClientOption {
+	return func(c *Client) {
+		c.insecureSkipVerify = true
+	}
+}
+
+// insecureHTTPClient returns a copy of hc whose transport skips TLS
+// certificate verification, or ok=false if its transport cannot be configured
+func insecureHTTPClient(hc *http.Client) (client *http.Client, ok bool) {
+	var transport *http.Transport
+	switch t := hc.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return hc, false
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+
+	copied := *hc
+	copied.Transport = transport
+	return &copied, true
+}
+
 // WithRateLimit sets the minimum delay between API calls
 func WithRateLimit(d time.Duration) ClientOption {
 	return func(c *Client) {
@@ -59,12 +231,111 @@ func WithRateLimit(d time.Duration) ClientOption {
 	}
 }
 
+// WithRetry retries rate-limited requests up to maxRetries times, waiting delay between attempts
+func WithRetry(maxRetries int, delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = delay
+	}
+}
+
+// WithRetryBudget caps the total number of retries across a range fetch (0 = unlimited)
+func WithRetryBudget(n int) ClientOption {
+	return func(c *Client) {
+		c.retryBudget = n
+	}
+}
+
+// WithMetrics sets a callback invoked after every API request, including failed ones
+func WithMetrics(fn MetricsFunc) ClientOption {
+	return func(c *Client) {
+		c.metrics = fn
+	}
+}
+
+// WithRequestLogger sets a callback invoked after every API request, including
+// failed ones, with the correlation ID of the operation that made it. Requests
+// shared through WithSingleflight report the ID of the caller that issued them.
+func WithRequestLogger(fn RequestLogFunc) ClientOption {
+	return func(c *Client) {
+		c.requestLog = fn
+	}
+}
+
+// WithWarnings sets a callback for non-fatal problems the client works around.
+// Without it they are not reported.
+func WithWarnings(fn WarningFunc) ClientOption {
+	return func(c *Client) {
+		c.warnings = fn
+	}
+}
+
+// warn reports a non-fatal problem to the warning callback, if set
+func (c *Client) warn(format string, args ...any) {
+	if c.warnings != nil {
+		c.warnings(fmt.Sprintf(format, args...))
+	}
+}
+
+// correlationIDKey is the context key for correlation IDs
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id, which is passed to the
+// request logger for every API request made with that context
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" if none is set
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// WithProgress sets a callback invoked after each day of a range fetch
+func WithProgress(fn ProgressFunc) ClientOption {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
+
+// WithSingleflight makes concurrent identical GET requests share a single API call
+func WithSingleflight() ClientOption {
+	return func(c *Client) {
+		c.flights = &flightGroup{}
+	}
+}
+
+// WithMINHistoryEndpoint sets the endpoint path used for MIN/TLX history,
+// for firmware that serves it somewhere other than device/tlx/tlx_data
+func WithMINHistoryEndpoint(endpoint string) ClientOption {
+	return func(c *Client) {
+		c.minHistoryEndpoint = endpoint
+	}
+}
+
+// WithDuplicateMode sets how GetPlantPower combines readings whose times
+// normalize to the same HH:MM (e.g. during DST fall-back). The default,
+// MergeReplace, keeps the reading with the later raw timestamp; MergeSum adds them.
+// An empty mode means MergeReplace; any other mode is ignored with a warning.
+func WithDuplicateMode(mode MergeMode) ClientOption {
+	return func(c *Client) {
+		c.duplicateMode = mode
+	}
+}
+
 // NewClient creates a new Growatt API client
 func NewClient(token string, opts ...ClientOption) *Client {
 	c := &Client{
-		baseURL:   DefaultBaseURL,
-		token:     token,
-		rateLimit: DefaultRateLimit,
+		baseURL:     DefaultBaseURL,
+		token:       token,
+		authHeader:  DefaultAuthHeader,
+		rateLimit:   DefaultRateLimit,
+		retryDelay:  DefaultRetryDelay,
+		retryBudget: DefaultRetryBudget,
+
+		minHistoryEndpoint: DefaultMINHistoryEndpoint,
+		duplicateMode:      MergeReplace,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
@@ -74,6 +345,22 @@ func NewClient(token string, opts ...ClientOption) *Client {
 		opt(c)
 	}
 
+	// Checked after all options so the warning reaches a later WithWarnings
+	if !c.duplicateMode.valid() {
+		c.warn("ignoring unknown duplicate mode %q, using %s", c.duplicateMode, MergeReplace)
+		c.duplicateMode = MergeReplace
+	}
+
+	// Applied after all options so it also covers a client from WithHTTPClient
+	if c.insecureSkipVerify {
+		var ok bool
+		if c.httpClient, ok = insecureHTTPClient(c.httpClient); ok {
+			c.warn("TLS certificate verification is disabled; use this only for testing")
+		} else {
+			c.warn("cannot disable TLS certificate verification on transport %T", c.httpClient.Transport)
+		}
+	}
+
 	return c
 }
 
@@ -90,6 +377,10 @@ func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
 		c.baseURL = baseURL
 	}
 
+	if header := os.Getenv(EnvAuthHeader); header != "" {
+		c.authHeader = header
+	}
+
 	return c, nil
 }
 
@@ -98,6 +389,11 @@ func (c *Client) SetRateLimit(d time.Duration) {
 	c.rateLimit = d
 }
 
+// RateLimit returns the minimum delay between API calls
+func (c *Client) RateLimit() time.Duration {
+	return c.rateLimit
+}
+
 // Token returns the current API token
 func (c *Client) Token() string {
 	return c.token
@@ -110,6 +406,9 @@ func (c *Client) BaseURL() string {
 
 // enforceRateLimit waits if necessary to respect rate limiting
 func (c *Client) enforceRateLimit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.rateLimit > 0 && !c.lastCall.IsZero() {
 		elapsed := time.Since(c.lastCall)
 		if elapsed < c.rateLimit {
@@ -119,10 +418,66 @@ func (c *Client) enforceRateLimit() {
 	c.lastCall = time.Now()
 }
 
+// retryBudget tracks retries remaining across a range fetch; nil means unlimited
+type retryBudget struct {
+	remaining int
+}
+
+// newRetryBudget returns a budget for one range fetch
+func (c *Client) newRetryBudget() *retryBudget {
+	if c.retryBudget <= 0 {
+		return nil
+	}
+	return &retryBudget{remaining: c.retryBudget}
+}
+
+// withRetry calls fn, retrying transient failures within the per-request
+// limit and the shared budget
+func (c *Client) withRetry(ctx context.Context, budget *retryBudget, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !IsRetryable(err) || attempt >= c.maxRetries {
+			return err
+		}
+
+		if budget != nil {
+			if budget.remaining <= 0 {
+				return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+			}
+			budget.remaining--
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// recordMetrics reports a request's duration to the metrics and request log
+// callbacks if set
+func (c *Client) recordMetrics(ctx context.Context, endpoint string, start time.Time, err error) {
+	dur := time.Since(start)
+	if c.metrics != nil {
+		c.metrics(endpoint, dur, err)
+	}
+	if c.requestLog != nil {
+		c.requestLog(CorrelationID(ctx), endpoint, dur, err)
+	}
+}
+
 // doRequest performs an HTTP request to the API
-func (c *Client) doRequest(ctx context.Context, method, endpoint string, params url.Values) ([]byte, error) {
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, params url.Values) (body []byte, err error) {
 	c.enforceRateLimit()
 
+	start := time.Now()
+	defer func() { c.recordMetrics(ctx, endpoint, start, err) }()
+
+	if method == http.MethodGet {
+		params = c.withDefaultParams(params)
+	}
+
 	fullURL := c.baseURL + endpoint
 	if len(params) > 0 {
 		fullURL += "?" + params.Encode()
@@ -133,7 +488,7 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, params
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("token", c.token)
+	req.Header.Set(c.authHeader, c.token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -142,28 +497,94 @@ func (c *Client) doRequest(ctx context.Context, method, endpoint string, params
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &HTTPError{StatusCode: resp.StatusCode}
+	}
+
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
+	if err := c.recordResponse(endpoint, body); err != nil {
+		return nil, err
+	}
+
 	return body, nil
 }
 
-// get performs a GET request
+// withDefaultParams returns params merged with the client's default query
+// parameters, leaving the caller's values untouched
+func (c *Client) withDefaultParams(params url.Values) url.Values {
+	if len(c.defaultParams) == 0 {
+		return params
+	}
+
+	merged := url.Values{}
+	for key, values := range c.defaultParams {
+		merged[key] = values
+	}
+	for key, values := range params {
+		merged[key] = values
+	}
+	return merged
+}
+
+// get performs a GET request, de-duplicating identical in-flight requests if enabled
 func (c *Client) get(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
-	return c.doRequest(ctx, http.MethodGet, endpoint, params)
+	if c.flights == nil {
+		return c.doRequest(ctx, http.MethodGet, endpoint, params)
+	}
+
+	key := endpoint + "?" + params.Encode()
+	return c.flights.do(ctx, key, func() ([]byte, error) {
+		return c.doRequest(ctx, http.MethodGet, endpoint, params)
+	})
+}
+
+// Raw performs a request and returns the body without checking error_code,
+// for inspecting new or undocumented endpoints. POST params are sent as a
+// form body; other methods send them as query parameters.
+func (c *Client) Raw(ctx context.Context, method, endpoint string, params url.Values) ([]byte, error) {
+	if method == http.MethodPost {
+		return c.postForm(ctx, endpoint, params)
+	}
+	return c.doRequest(ctx, method, endpoint, params)
+}
+
+// CallAPI calls an endpoint the library does not wrap and parses the response
+// data into T, with the same error checking as the built-in methods.
+// POST params are sent as a form body; other methods send them as query parameters.
+func CallAPI[T any](ctx context.Context, c *Client, method, endpoint string, params url.Values) (*T, error) {
+	body, err := c.Raw(ctx, method, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse[T](body)
+}
+
+// checkCount compares a response's reported count with the items parsed when
+// strict parsing is enabled. A count of 0 is treated as not reported.
+func (c *Client) checkCount(endpoint string, count, items int) error {
+	if !c.strictParsing || count == 0 || count == items {
+		return nil
+	}
+	return fmt.Errorf("%w: %s reported %d items, got %d", ErrCountMismatch, endpoint, count, items)
 }
 
 // checkResponse checks if the API response indicates an error
 func checkResponse(body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ErrEmptyResponse
+	}
+
 	var resp Response[any]
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return fmt.Errorf("parsing response: %w", err)
 	}
 
 	if resp.ErrorCode != 0 {
-		return NewAPIError(resp.ErrorCode, resp.ErrorMsg)
+		return NewAPIError(resp.ErrorCode.Int(), resp.ErrorMsg)
 	}
 
 	return nil
@@ -171,14 +592,38 @@ func checkResponse(body []byte) error {
 
 // parseResponse parses a JSON response into the given type
 func parseResponse[T any](body []byte) (*T, error) {
+	data, _, err := parseResponseRaw[T](body)
+	return data, err
+}
+
+// parseResponseRaw is parseResponse that also returns the undecoded data field
+func parseResponseRaw[T any](body []byte) (*T, json.RawMessage, error) {
 	if err := checkResponse(body); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var resp Response[T]
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, fmt.Errorf("parsing response data: %w", err)
+	// Some success responses carry "data": "" instead of an object
+	var raw Response[json.RawMessage]
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, fmt.Errorf("parsing response data: %w", err)
+	}
+	if isEmptyData(raw.Data) {
+		return new(T), raw.Data, nil
 	}
 
-	return &resp.Data, nil
+	var data T
+	if err := json.Unmarshal(raw.Data, &data); err != nil {
+		return nil, nil, fmt.Errorf("parsing response data: %w", err)
+	}
+
+	return &data, raw.Data, nil
+}
+
+// isEmptyData reports whether a response's data field is missing, null or ""
+func isEmptyData(data json.RawMessage) bool {
+	switch string(bytes.TrimSpace(data)) {
+	case "", "null", `""`:
+		return true
+	}
+	return false
 }
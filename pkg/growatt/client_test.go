@@ -2,9 +2,12 @@ package growatt
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -48,6 +51,34 @@ func TestNewClientWithOptions(t *testing.T) {
 	}
 }
 
+func TestWithRegion(t *testing.T) {
+	tests := []struct {
+		region   Region
+		expected string
+	}{
+		{RegionEurope, "https://openapi.growatt.com/v1/"},
+		{RegionNorthAmerica, "https://openapi-us.growatt.com/v1/"},
+		{RegionAustralia, "https://openapi-au.growatt.com/v1/"},
+		{RegionChina, "https://openapi-cn.growatt.com/v1/"},
+		{Region("mars"), DefaultBaseURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.region), func(t *testing.T) {
+			client := NewClient("test", WithRegion(tt.region))
+			if client.BaseURL() != tt.expected {
+				t.Errorf("expected base URL %q, got %q", tt.expected, client.BaseURL())
+			}
+		})
+	}
+
+	// A later WithBaseURL still overrides the region
+	client := NewClient("test", WithRegion(RegionChina), WithBaseURL("https://custom.api.com/v1/"))
+	if client.BaseURL() != "https://custom.api.com/v1/" {
+		t.Errorf("expected custom base URL, got %q", client.BaseURL())
+	}
+}
+
 func TestNewClientFromEnv(t *testing.T) {
 	// Test missing token
 	os.Unsetenv(EnvAPIKey)
@@ -83,6 +114,51 @@ func TestNewClientFromEnv(t *testing.T) {
 	}
 }
 
+func TestNewClientFromEnv_AuthHeader(t *testing.T) {
+	var gotHeaders []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = append(gotHeaders, r.Header.Clone())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "plants": []}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvAPIKey, "env-token")
+	t.Setenv(EnvAuthHeader, "X-Growatt-Token")
+
+	client, err := NewClientFromEnv(WithBaseURL(server.URL+"/"), WithRateLimit(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.ListPlants(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.postForm(ctx, "device/tlx/tlx_data", url.Values{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, h := range gotHeaders {
+		if h.Get("X-Growatt-Token") != "env-token" {
+			t.Errorf("request %d: expected X-Growatt-Token header %q, got %q", i, "env-token", h.Get("X-Growatt-Token"))
+		}
+		if h.Get("token") != "" {
+			t.Errorf("request %d: expected no token header, got %q", i, h.Get("token"))
+		}
+	}
+
+	// Without the variable the default header is used
+	t.Setenv(EnvAuthHeader, "")
+	client, err = NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.authHeader != DefaultAuthHeader {
+		t.Errorf("expected default auth header %q, got %q", DefaultAuthHeader, client.authHeader)
+	}
+}
+
 func TestClientRequest(t *testing.T) {
 	// Create test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -113,6 +189,71 @@ func TestClientRequest(t *testing.T) {
 	}
 }
 
+func TestRaw(t *testing.T) {
+	const errorBody = `{"error_code": 10011, "error_msg": "error_permission_denied", "data": ""}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/new/endpoint" {
+			t.Errorf("expected path /new/endpoint, got %s", r.URL.Path)
+		}
+		r.ParseForm()
+		if r.Form.Get("plant_id") != "12345" {
+			t.Errorf("%s: expected plant_id 12345, got %q", r.Method, r.Form.Get("plant_id"))
+		}
+		w.Write([]byte(errorBody))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+	)
+
+	params := url.Values{"plant_id": {"12345"}}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		body, err := client.Raw(context.Background(), method, "new/endpoint", params)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", method, err)
+		}
+		if string(body) != errorBody {
+			t.Errorf("%s: expected raw body, got %q", method, body)
+		}
+	}
+}
+
+func TestCallAPI(t *testing.T) {
+	type inverterTemp struct {
+		Serial      string    `json:"tlx_sn"`
+		Temperature FlexFloat `json:"temperature"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tlx_sn") == "ABC123456" {
+			w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"tlx_sn": "ABC123456", "temperature": "41.5"}}`))
+			return
+		}
+		w.Write([]byte(`{"error_code": 10011, "error_msg": "error_permission_denied", "data": ""}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0))
+	ctx := context.Background()
+
+	data, err := CallAPI[inverterTemp](ctx, client, http.MethodGet, "device/tlx/temperature", url.Values{"tlx_sn": {"ABC123456"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Serial != "ABC123456" || data.Temperature.Float64() != 41.5 {
+		t.Errorf("unexpected data: %+v", data)
+	}
+
+	// API errors are checked as for built-in methods
+	_, err = CallAPI[inverterTemp](ctx, client, http.MethodGet, "device/tlx/temperature", url.Values{"tlx_sn": {"OTHER12345"}})
+	if !IsPermissionDenied(err) {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+}
+
 func TestCheckResponse(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -137,6 +278,17 @@ func TestCheckResponse(t *testing.T) {
 			wantErr: true,
 			errCode: 10012,
 		},
+		{
+			name:    "quoted success",
+			body:    `{"error_code": "0", "error_msg": "success", "data": {}}`,
+			wantErr: false,
+		},
+		{
+			name:    "quoted permission denied",
+			body:    `{"error_code": "10011", "error_msg": "error_permission_denied", "data": ""}`,
+			wantErr: true,
+			errCode: 10011,
+		},
 	}
 
 	for _, tt := range tests {
@@ -164,6 +316,56 @@ func TestCheckResponse(t *testing.T) {
 	}
 }
 
+func TestCheckResponse_Empty(t *testing.T) {
+	for _, body := range []string{"", " \n\t"} {
+		if err := checkResponse([]byte(body)); !errors.Is(err, ErrEmptyResponse) {
+			t.Errorf("body %q: expected ErrEmptyResponse, got %v", body, err)
+		}
+	}
+}
+
+func TestParseResponse_EmptyStringData(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty string", `{"error_code": 0, "data": ""}`},
+		{"null", `{"error_code": 0, "error_msg": "success", "data": null}`},
+		{"missing", `{"error_code": 0}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseResponse[PlantListData]([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data == nil || data.Count != 0 || len(data.Plants) != 0 {
+				t.Errorf("expected zero value, got %+v", data)
+			}
+		})
+	}
+
+	// Error responses with empty data still report the API error
+	if _, err := parseResponse[PlantListData]([]byte(`{"error_code": 10011, "error_msg": "error_permission_denied", "data": ""}`)); !IsPermissionDenied(err) {
+		t.Errorf("expected permission denied error, got %v", err)
+	}
+}
+
+func TestParseResponse_QuotedErrorCode(t *testing.T) {
+	data, err := parseResponse[PlantListData]([]byte(`{"error_code": "0", "error_msg": "success", "data": {"count": 1, "plants": [{"plant_id": "12345"}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Plants) != 1 || data.Plants[0].PlantID != "12345" {
+		t.Errorf("unexpected data: %+v", data)
+	}
+
+	if _, err := parseResponse[PlantListData]([]byte(`{"error_code": "10012", "error_msg": "", "data": ""}`)); !IsPlantNotFound(err) {
+		t.Errorf("expected plant not found error, got %v", err)
+	}
+}
+
 func TestSetRateLimit(t *testing.T) {
 	client := NewClient("test")
 	client.SetRateLimit(10 * time.Second)
@@ -171,4 +373,298 @@ func TestSetRateLimit(t *testing.T) {
 	if client.rateLimit != 10*time.Second {
 		t.Errorf("expected rate limit %v, got %v", 10*time.Second, client.rateLimit)
 	}
+	if client.RateLimit() != 10*time.Second {
+		t.Errorf("expected RateLimit() %v, got %v", 10*time.Second, client.RateLimit())
+	}
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	client := NewClient("test", WithInsecureSkipVerify())
+
+	if client.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("expected timeout %v, got %v", DefaultTimeout, client.httpClient.Timeout)
+	}
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.httpClient.Transport)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify to be enabled")
+	}
+
+	// Default transport must remain untouched
+	if http.DefaultTransport.(*http.Transport).TLSClientConfig != nil &&
+		http.DefaultTransport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("default transport should not be modified")
+	}
+}
+
+func TestWithInsecureSkipVerify_CopiesCallerClient(t *testing.T) {
+	callerTransport := &http.Transport{}
+	caller := &http.Client{Timeout: 5 * time.Second, Transport: callerTransport}
+
+	var warnings []string
+	client := NewClient("test",
+		WithInsecureSkipVerify(),
+		WithHTTPClient(caller),
+		WithWarnings(func(msg string) { warnings = append(warnings, msg) }),
+	)
+
+	if client.httpClient == caller || caller.Transport != callerTransport ||
+		(callerTransport.TLSClientConfig != nil && callerTransport.TLSClientConfig.InsecureSkipVerify) {
+		t.Error("expected the caller's client and transport to be left unchanged")
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected the caller's timeout to be kept, got %v", client.httpClient.Timeout)
+	}
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify to be enabled on the copy")
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "TLS certificate verification is disabled") {
+		t.Errorf("expected one warning, got %v", warnings)
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {}}`))
+	}))
+
+	type call struct {
+		endpoint string
+		dur      time.Duration
+		err      error
+	}
+	var calls []call
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithMetrics(func(endpoint string, dur time.Duration, err error) {
+			calls = append(calls, call{endpoint, dur, err})
+		}),
+	)
+
+	ctx := context.Background()
+	if _, err := client.get(ctx, "plant/list", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.postForm(ctx, "device/tlx/tlx_data", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Error path: server is gone
+	server.Close()
+	if _, err := client.get(ctx, "plant/data", nil); err == nil {
+		t.Fatal("expected error from closed server")
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 metrics calls, got %d", len(calls))
+	}
+
+	for i, endpoint := range []string{"plant/list", "device/tlx/tlx_data", "plant/data"} {
+		if calls[i].endpoint != endpoint {
+			t.Errorf("call %d: expected endpoint %q, got %q", i, endpoint, calls[i].endpoint)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if calls[i].err != nil {
+			t.Errorf("call %d: unexpected error: %v", i, calls[i].err)
+		}
+		if calls[i].dur < 10*time.Millisecond || calls[i].dur > 5*time.Second {
+			t.Errorf("call %d: implausible duration %v", i, calls[i].dur)
+		}
+	}
+
+	if calls[2].err == nil {
+		t.Error("expected error to be reported for failed request")
+	}
+}
+
+func TestWithRequestLogger_CorrelationID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {}}`))
+	}))
+	defer server.Close()
+
+	type call struct {
+		id       string
+		endpoint string
+	}
+	var calls []call
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithRequestLogger(func(correlationID, endpoint string, dur time.Duration, err error) {
+			calls = append(calls, call{correlationID, endpoint})
+		}),
+	)
+
+	ctx := WithCorrelationID(context.Background(), "export-42")
+	if _, err := client.get(ctx, "plant/list", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.postForm(ctx, "device/tlx/tlx_data", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.get(context.Background(), "plant/data", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []call{
+		{"export-42", "plant/list"},
+		{"export-42", "device/tlx/tlx_data"},
+		{"", "plant/data"},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %d logger calls, got %d", len(expected), len(calls))
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %+v, got %+v", i, expected[i], calls[i])
+		}
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	if id := CorrelationID(context.Background()); id != "" {
+		t.Errorf("expected empty ID, got %q", id)
+	}
+	if id := CorrelationID(WithCorrelationID(context.Background(), "abc")); id != "abc" {
+		t.Errorf("expected %q, got %q", "abc", id)
+	}
+}
+
+func TestWithStrictParsing_CountMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		call func(*Client) error
+	}{
+		{
+			name: "plant list",
+			body: `{"error_code": 0, "data": {"count": 3, "plants": [{"plant_id": 1}]}}`,
+			call: func(c *Client) error {
+				_, err := c.ListPlants(context.Background())
+				return err
+			},
+		},
+		{
+			name: "device list",
+			body: `{"error_code": 0, "data": {"count": 2, "devices": [{"device_sn": "ABC123456"}]}}`,
+			call: func(c *Client) error {
+				_, err := c.ListDevices(context.Background(), "12345")
+				return err
+			},
+		},
+		{
+			name: "plant power",
+			body: `{"error_code": 0, "data": {"count": 288, "powers": {"12:00": 100}}}`,
+			call: func(c *Client) error {
+				_, err := c.GetPlantPowerRaw(context.Background(), "12345", time.Now())
+				return err
+			},
+		},
+		{
+			name: "plant energy",
+			body: `{"error_code": 0, "data": {"count": 7, "datas": {"2025-01-01": 30.5}}}`,
+			call: func(c *Client) error {
+				_, err := c.GetPlantEnergy(context.Background(), "12345", "2025-01-01", "2025-01-07", TimeUnitDay)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			lenient := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0))
+			if err := tt.call(lenient); err != nil {
+				t.Errorf("expected no error without strict parsing, got %v", err)
+			}
+
+			strict := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0), WithStrictParsing())
+			if err := tt.call(strict); !errors.Is(err, ErrCountMismatch) {
+				t.Errorf("expected ErrCountMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWithDefaultQueryParam(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("lang"); got != "en" {
+			t.Errorf("expected lang=en, got %q", got)
+		}
+		if got := q.Get("plant_id"); got != "12345" {
+			t.Errorf("expected plant_id=12345, got %q", got)
+		}
+		if got := q.Get("account"); got != "request" {
+			t.Errorf("expected request param to take precedence, got account=%q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithDefaultQueryParam("lang", "en"),
+		WithDefaultQueryParam("account", "default"),
+	)
+
+	params := url.Values{}
+	params.Set("plant_id", "12345")
+	params.Set("account", "request")
+
+	if _, err := client.get(context.Background(), "test", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(params) != 2 {
+		t.Errorf("caller's params were modified: %v", params)
+	}
+}
+
+func TestWithDuplicateMode(t *testing.T) {
+	tests := []struct {
+		mode     MergeMode
+		expected MergeMode
+		warnings int
+	}{
+		{MergeSum, MergeSum, 0},
+		{MergeReplace, MergeReplace, 0},
+		{"", "", 0},
+		{"average", MergeReplace, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			var warnings []string
+			c := NewClient("test-token",
+				WithDuplicateMode(tt.mode),
+				WithWarnings(func(msg string) { warnings = append(warnings, msg) }),
+			)
+			if c.duplicateMode != tt.expected {
+				t.Errorf("expected mode %q, got %q", tt.expected, c.duplicateMode)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, warnings)
+			}
+		})
+	}
 }
@@ -2,6 +2,7 @@ package growatt
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,24 +14,115 @@ import (
 
 // ListDevices returns all devices for a plant
 func (c *Client) ListDevices(ctx context.Context, plantID string) ([]Device, error) {
+	devices, _, err := c.ListDevicesRaw(ctx, plantID)
+	return devices, err
+}
+
+// ListDevicesRaw returns the devices for a plant together with the raw data
+// object of the response, for inspecting fields Device does not decode
+func (c *Client) ListDevicesRaw(ctx context.Context, plantID string) ([]Device, json.RawMessage, error) {
 	params := url.Values{}
 	params.Set("plant_id", plantID)
 
 	body, err := c.get(ctx, "device/list", params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, raw, err := parseResponseRaw[DeviceListData](body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.checkCount("device/list", data.Count, len(data.Devices)); err != nil {
+		return nil, nil, err
+	}
+
+	return data.Devices, raw, nil
+}
+
+// ListDevicesByType returns the devices for a plant matching any of the given kinds
+func (c *Client) ListDevicesByType(ctx context.Context, plantID string, kinds ...DeviceKind) ([]Device, error) {
+	devices, err := c.ListDevices(ctx, plantID)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := parseResponse[DeviceListData](body)
+	var result []Device
+	for _, d := range devices {
+		for _, kind := range kinds {
+			if d.Kind() == kind {
+				result = append(result, d)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ValidateDeviceSN checks that serial looks like a Growatt device serial number:
+// 6-20 alphanumeric characters and not purely numeric (which is usually a plant ID)
+func ValidateDeviceSN(serial string) error {
+	if serial == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidDeviceSN)
+	}
+	if len(serial) < 6 || len(serial) > 20 {
+		return fmt.Errorf("%w: %q has unexpected length %d", ErrInvalidDeviceSN, serial, len(serial))
+	}
+
+	hasLetter := false
+	for _, r := range serial {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+			hasLetter = true
+		case r >= '0' && r <= '9':
+		default:
+			return fmt.Errorf("%w: %q contains non-alphanumeric character %q", ErrInvalidDeviceSN, serial, r)
+		}
+	}
+	if !hasLetter {
+		return fmt.Errorf("%w: %q is all digits (is this a plant ID?)", ErrInvalidDeviceSN, serial)
+	}
+
+	return nil
+}
+
+// GetDataloggerSignal returns the signal strength history of a datalogger, sorted by time
+func (c *Client) GetDataloggerSignal(ctx context.Context, datalogSN string) ([]SignalPoint, error) {
+	if err := ValidateDeviceSN(datalogSN); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("datalog_sn", datalogSN)
+
+	body, err := c.get(ctx, "device/datalogger/signal", params)
 	if err != nil {
 		return nil, err
 	}
 
-	return data.Devices, nil
+	data, err := parseResponse[DataloggerSignalData](body)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkCount("device/datalogger/signal", data.Count, len(data.Datas)); err != nil {
+		return nil, err
+	}
+
+	points := data.Datas
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time < points[j].Time
+	})
+
+	return points, nil
 }
 
 // GetMINInverterDetails returns details for a MIN/TLX inverter
 func (c *Client) GetMINInverterDetails(ctx context.Context, serial string) (*MINInverterData, error) {
+	if err := ValidateDeviceSN(serial); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{}
 	params.Set("tlx_sn", serial)
 
@@ -75,6 +167,54 @@ type MINHistoryDataPoint struct {
 	Ipv2  FlexFloat `json:"ipv2"`  // PV2 Current
 	Vac1  FlexFloat `json:"vac1"`  // AC Voltage
 	Iac1  FlexFloat `json:"iac1"`  // AC Current
+	Pf    FlexFloat `json:"pf"`    // Power factor
+	Qac   FlexFloat `json:"qac"`   // Reactive power (var)
+
+	Temperature FlexFloat `json:"temperature"` // Inverter temperature (C)
+}
+
+// minHistoryFields maps JSON field names to MIN history values
+var minHistoryFields = map[string]func(MINHistoryDataPoint) FlexFloat{
+	"pac":         func(d MINHistoryDataPoint) FlexFloat { return d.Pac },
+	"ppv":         func(d MINHistoryDataPoint) FlexFloat { return d.Ppv },
+	"vpv1":        func(d MINHistoryDataPoint) FlexFloat { return d.Vpv1 },
+	"vpv2":        func(d MINHistoryDataPoint) FlexFloat { return d.Vpv2 },
+	"ipv1":        func(d MINHistoryDataPoint) FlexFloat { return d.Ipv1 },
+	"ipv2":        func(d MINHistoryDataPoint) FlexFloat { return d.Ipv2 },
+	"vac1":        func(d MINHistoryDataPoint) FlexFloat { return d.Vac1 },
+	"iac1":        func(d MINHistoryDataPoint) FlexFloat { return d.Iac1 },
+	"pf":          func(d MINHistoryDataPoint) FlexFloat { return d.Pf },
+	"qac":         func(d MINHistoryDataPoint) FlexFloat { return d.Qac },
+	"temperature": func(d MINHistoryDataPoint) FlexFloat { return d.Temperature },
+}
+
+// MINHistoryFields returns the sorted names of the numeric MIN history fields
+func MINHistoryFields() []string {
+	names := make([]string, 0, len(minHistoryFields))
+	for name := range minHistoryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClockTime returns the time of day of the data point as HH:MM
+func (d MINHistoryDataPoint) ClockTime() string {
+	return normalizeTime(d.Time)
+}
+
+// StringPower returns the DC power (W) of each MPPT input, computed as voltage times current
+func (d MINHistoryDataPoint) StringPower() (pv1, pv2 float64) {
+	return d.Vpv1.Float64() * d.Ipv1.Float64(), d.Vpv2.Float64() * d.Ipv2.Float64()
+}
+
+// Field returns the numeric value of the named field (its JSON name, e.g. "pac")
+func (d MINHistoryDataPoint) Field(name string) (float64, error) {
+	get, ok := minHistoryFields[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown MIN history field %q", name)
+	}
+	return get(d).Float64(), nil
 }
 
 // MINHistoryResponse is the response from MIN historical data endpoint
@@ -83,15 +223,124 @@ type MINHistoryResponse struct {
 	Datas []MINHistoryDataPoint `json:"datas"`
 }
 
+// MINPowerField selects which MIN history field is used as the power value
+type MINPowerField string
+
+const (
+	MINPowerPac MINPowerField = "pac" // AC output power
+	MINPowerPpv MINPowerField = "ppv" // PV input power
+)
+
+// ParseMINHistory converts MIN history points to parsed power data using AC power (Pac)
+func ParseMINHistory(points []MINHistoryDataPoint, date time.Time, loc *time.Location) ([]ParsedPowerData, error) {
+	return ParseMINHistoryField(points, date, loc, MINPowerPac)
+}
+
+// ParseMINHistoryField converts MIN history points to parsed power data using the given power field.
+// Points with unparseable times are skipped; results are sorted by time.
+func ParseMINHistoryField(points []MINHistoryDataPoint, date time.Time, loc *time.Location, field MINPowerField) ([]ParsedPowerData, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+
+	result := make([]ParsedPowerData, 0, len(points))
+	for _, p := range points {
+		power, err := p.Field(string(field))
+		if err != nil {
+			return nil, err
+		}
+
+		timeStr := normalizeTime(p.Time)
+		t, err := time.Parse("15:04", timeStr)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, ParsedPowerData{
+			Date:   day,
+			Time:   timeStr,
+			Power:  power,
+			Hour:   t.Hour(),
+			Minute: t.Minute(),
+		})
+	}
+
+	// Sort by time
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time < result[j].Time
+	})
+
+	return result, nil
+}
+
+// MINHistoryDay is one day of detailed MIN/TLX history
+type MINHistoryDay struct {
+	Serial string
+	Date   string
+	Points []MINHistoryDataPoint
+}
+
+// PowerData converts the day to PowerData, using Pac (AC power) as the power value
+func (d *MINHistoryDay) PowerData() *PowerData {
+	powers := make([]PowerDataPoint, 0, len(d.Points))
+	for _, p := range d.Points {
+		powers = append(powers, PowerDataPoint{
+			Time:  p.ClockTime(),
+			Power: p.Pac.Float64(),
+		})
+	}
+
+	// Sort by time
+	sort.Slice(powers, func(i, j int) bool {
+		return powers[i].Time < powers[j].Time
+	})
+
+	return &PowerData{
+		PlantID: FlexString(d.Serial),
+		Date:    d.Date,
+		Powers:  powers,
+	}
+}
+
 // GetMINInverterHistory returns historical data for a MIN/TLX inverter
 // Note: Maximum date range is 7 days
 func (c *Client) GetMINInverterHistory(ctx context.Context, serial string, date time.Time, timezone string) (*PowerData, error) {
+	day, err := c.getMINInverterHistoryDay(ctx, serial, date, timezone, c.newRetryBudget())
+	if err != nil {
+		return nil, err
+	}
+	return day.PowerData(), nil
+}
+
+// GetMINInverterHistoryDay returns one day of detailed MIN/TLX history with all telemetry fields
+func (c *Client) GetMINInverterHistoryDay(ctx context.Context, serial string, date time.Time, timezone string) (*MINHistoryDay, error) {
+	return c.getMINInverterHistoryDay(ctx, serial, date, timezone, c.newRetryBudget())
+}
+
+// getMINInverterHistoryDay fetches one day of MIN history, drawing retries from budget
+func (c *Client) getMINInverterHistoryDay(ctx context.Context, serial string, date time.Time, timezone string, budget *retryBudget) (*MINHistoryDay, error) {
+	if err := ValidateDeviceSN(serial); err != nil {
+		return nil, err
+	}
+
 	if timezone == "" {
 		timezone = "US/Central" // Default timezone
 	}
 
 	dateStr := date.Format("2006-01-02")
 
+	cacheable := c.historyCacheable(dateStr, timezone)
+	if cacheable {
+		points, ok, err := c.readHistoryCache(serial, dateStr)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &MINHistoryDay{Serial: serial, Date: dateStr, Points: points}, nil
+		}
+	}
+
 	reqBody := MINHistoryRequest{
 		DeviceSN:   serial,
 		StartDate:  dateStr,
@@ -101,68 +350,122 @@ func (c *Client) GetMINInverterHistory(ctx context.Context, serial string, date
 		PerPage:    100, // API max is 100
 	}
 
-	body, err := c.postForm(ctx, "device/tlx/tlx_data", reqBody.ToFormData())
-	if err != nil {
-		return nil, err
-	}
+	var histResp *MINHistoryResponse
+	err := c.withRetry(ctx, budget, func() error {
+		body, err := c.postForm(ctx, c.minHistoryEndpoint, reqBody.ToFormData())
+		if err != nil {
+			return err
+		}
 
-	histResp, err := parseResponse[MINHistoryResponse](body)
+		histResp, err = parseResponse[MINHistoryResponse](body)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert to PowerData format
-	powers := make([]PowerDataPoint, 0, len(histResp.Datas))
-	for _, d := range histResp.Datas {
-		timeStr := normalizeTime(d.Time)
-		// Use Pac (AC power) as the power value
-		powers = append(powers, PowerDataPoint{
-			Time:  timeStr,
-			Power: d.Pac.Float64(),
-		})
-	}
-
 	// Sort by time
-	sort.Slice(powers, func(i, j int) bool {
-		return powers[i].Time < powers[j].Time
+	points := histResp.Datas
+	sort.SliceStable(points, func(i, j int) bool {
+		return normalizeTime(points[i].Time) < normalizeTime(points[j].Time)
 	})
 
-	return &PowerData{
-		PlantID: FlexString(serial),
-		Date:    dateStr,
-		Powers:  powers,
+	// An empty day may be a logger that has not uploaded yet, so it is
+	// refetched rather than cached
+	if cacheable && len(points) > 0 {
+		if err := c.writeHistoryCache(serial, dateStr, points); err != nil {
+			return nil, err
+		}
+	}
+
+	return &MINHistoryDay{
+		Serial: serial,
+		Date:   dateStr,
+		Points: points,
 	}, nil
 }
 
 // GetMINInverterHistoryRange fetches historical data for a date range
-// Note: API has 7-day maximum per request, this method handles pagination
+// Note: API has 7-day maximum per request, this method handles pagination.
+// Retries for all days are drawn from a single shared retry budget.
 func (c *Client) GetMINInverterHistoryRange(ctx context.Context, serial string, from, to time.Time, timezone string) ([]PowerData, error) {
 	var results []PowerData
+	err := c.EachMINInverterHistoryRange(ctx, serial, from, to, timezone, func(data *PowerData) error {
+		results = append(results, *data)
+		return nil
+	})
+	return results, err
+}
+
+// EachMINInverterHistoryRange is the streaming form of GetMINInverterHistoryRange,
+// passing each day's power data to fn as it is fetched
+func (c *Client) EachMINInverterHistoryRange(ctx context.Context, serial string, from, to time.Time, timezone string, fn func(*PowerData) error) error {
+	return c.EachMINInverterHistoryDay(ctx, serial, from, to, timezone, func(day *MINHistoryDay) error {
+		return fn(day.PowerData())
+	})
+}
+
+// GetMINInverterHistoryDayRange fetches detailed history for each day in a date range.
+// Retries for all days are drawn from a single shared retry budget.
+func (c *Client) GetMINInverterHistoryDayRange(ctx context.Context, serial string, from, to time.Time, timezone string) ([]MINHistoryDay, error) {
+	var results []MINHistoryDay
+	err := c.EachMINInverterHistoryDay(ctx, serial, from, to, timezone, func(day *MINHistoryDay) error {
+		results = append(results, *day)
+		return nil
+	})
+	return results, err
+}
+
+// EachMINInverterHistoryDay fetches detailed history for each day in a date
+// range, passing each day to fn as it arrives instead of collecting the range,
+// so long ranges can be processed without holding every day in memory. An
+// error from fn stops the fetch and is returned. Retries for all days are
+// drawn from a single shared retry budget.
+func (c *Client) EachMINInverterHistoryDay(ctx context.Context, serial string, from, to time.Time, timezone string, fn func(*MINHistoryDay) error) error {
+	if err := ValidateDeviceSN(serial); err != nil {
+		return err
+	}
+
+	budget := c.newRetryBudget()
+	total := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		total++
+	}
 
+	done := 0
 	current := from
 	for !current.After(to) {
 		select {
 		case <-ctx.Done():
-			return results, ctx.Err()
+			return ctx.Err()
 		default:
 		}
 
-		data, err := c.GetMINInverterHistory(ctx, serial, current, timezone)
+		day, err := c.getMINInverterHistoryDay(ctx, serial, current, timezone, budget)
 		if err != nil {
-			return results, fmt.Errorf("fetching MIN history for %s: %w", current.Format("2006-01-02"), err)
+			return fmt.Errorf("fetching MIN history for %s: %w", current.Format("2006-01-02"), err)
 		}
 
-		results = append(results, *data)
+		if err := fn(day); err != nil {
+			return err
+		}
+		done++
+		if c.progress != nil {
+			c.progress(done, total, current)
+		}
 		current = current.AddDate(0, 0, 1)
 	}
 
-	return results, nil
+	return nil
 }
 
 // postForm performs a POST request with form-encoded body
-func (c *Client) postForm(ctx context.Context, endpoint string, data url.Values) ([]byte, error) {
+func (c *Client) postForm(ctx context.Context, endpoint string, data url.Values) (respBody []byte, err error) {
 	c.enforceRateLimit()
 
+	start := time.Now()
+	defer func() { c.recordMetrics(ctx, endpoint, start, err) }()
+
 	fullURL := c.baseURL + endpoint
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURL, strings.NewReader(data.Encode()))
@@ -170,7 +473,7 @@ func (c *Client) postForm(ctx context.Context, endpoint string, data url.Values)
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("token", c.token)
+	req.Header.Set(c.authHeader, c.token)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := c.httpClient.Do(req)
@@ -179,10 +482,18 @@ func (c *Client) postForm(ctx context.Context, endpoint string, data url.Values)
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &HTTPError{StatusCode: resp.StatusCode}
+	}
+
+	respBody, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
+	if err := c.recordResponse(endpoint, respBody); err != nil {
+		return nil, err
+	}
+
 	return respBody, nil
 }
@@ -2,8 +2,13 @@ package growatt
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestListDevices(t *testing.T) {
@@ -47,6 +52,85 @@ func TestListDevices(t *testing.T) {
 	}
 }
 
+func TestListDevices_AlternativeKeys(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "device_list_inverters.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0), WithStrictParsing())
+
+	devices, err := client.ListDevices(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices))
+	}
+	if devices[0].DeviceSN.String() != "ABC123456" || devices[1].DeviceSN.String() != "DEF654321" {
+		t.Errorf("unexpected devices: %s, %s", devices[0].DeviceSN.String(), devices[1].DeviceSN.String())
+	}
+}
+
+func TestListDevicesByType(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "device_list_mixed.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		kinds    []DeviceKind
+		expected []string
+	}{
+		{
+			name:     "inverters only",
+			kinds:    []DeviceKind{DeviceKindInverter, DeviceKindMIN},
+			expected: []string{"ABC123456", "INV000001"},
+		},
+		{
+			name:     "storage only",
+			kinds:    []DeviceKind{DeviceKindStorage},
+			expected: []string{"BAT000001"},
+		},
+		{
+			name:     "unknown types",
+			kinds:    []DeviceKind{DeviceKindUnknown},
+			expected: []string{"DL0000001"},
+		},
+		{
+			name:     "no kinds",
+			kinds:    nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices, err := client.ListDevicesByType(ctx, "12345", tt.kinds...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(devices) != len(tt.expected) {
+				t.Fatalf("expected %d devices, got %d", len(tt.expected), len(devices))
+			}
+
+			for i, d := range devices {
+				if d.DeviceSN.String() != tt.expected[i] {
+					t.Errorf("expected device %q, got %q", tt.expected[i], d.DeviceSN.String())
+				}
+			}
+		})
+	}
+}
+
 func TestGetMINInverterDetails(t *testing.T) {
 	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/device/tlx/tlx_data_info" {
@@ -86,4 +170,552 @@ func TestGetMINInverterDetails(t *testing.T) {
 	if inverter.Temperature.Float64() != 42.5 {
 		t.Errorf("expected temperature %f, got %f", 42.5, inverter.Temperature.Float64())
 	}
+
+	if inverter.Pf.Float64() != 0.98 {
+		t.Errorf("expected power factor %f, got %f", 0.98, inverter.Pf.Float64())
+	}
+
+	if inverter.Qac.Float64() != -310.4 {
+		t.Errorf("expected reactive power %f, got %f", -310.4, inverter.Qac.Float64())
+	}
+
+	if inverter.Model != "MIN 7600TL-XH-US" {
+		t.Errorf("expected model MIN 7600TL-XH-US, got %s", inverter.Model)
+	}
+
+	if inverter.FwVersion != "TJAA1009" || inverter.DSPVersion != "TJAA-09" || inverter.ARMVersion != "ZAAA-06" {
+		t.Errorf("unexpected firmware versions: fw=%s dsp=%s arm=%s", inverter.FwVersion, inverter.DSPVersion, inverter.ARMVersion)
+	}
+
+	if inverter.RatedPower.Float64() != 7600 {
+		t.Errorf("expected rated power %f, got %f", 7600.0, inverter.RatedPower.Float64())
+	}
+}
+
+func TestGetMINInverterHistoryRange_RetryBudget(t *testing.T) {
+	// Every day is rate limited on its first attempt and succeeds on the second
+	attempts := map[string]int{}
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseForm()
+		day := r.PostForm.Get("start_date")
+		attempts[day]++
+
+		w.Header().Set("Content-Type", "application/json")
+		if attempts[day] == 1 {
+			w.Write([]byte(`{"error_code": 10012, "error_msg": "error_frequently_access", "data": ""}`))
+			return
+		}
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "datas": []}}`))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithRetry(5, 0),
+		WithRetryBudget(2),
+	)
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 4, 0, 0, 0, 0, time.UTC)
+
+	results, err := client.GetMINInverterHistoryRange(context.Background(), "ABC123456", from, to, "UTC")
+	if err == nil {
+		t.Fatal("expected error after retry budget exhausted, got nil")
+	}
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("expected ErrRetryBudgetExhausted, got %v", err)
+	}
+	if !IsRateLimited(err) {
+		t.Errorf("expected underlying rate limit error to be preserved, got %v", err)
+	}
+
+	// Days 1 and 2 each use one retry; day 3 has none left
+	if len(results) != 2 {
+		t.Errorf("expected 2 days fetched before giving up, got %d", len(results))
+	}
+	if requests != 5 {
+		t.Errorf("expected 5 requests, got %d", requests)
+	}
+}
+
+func TestGetMINInverterHistory_RetryLimit(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 10012, "error_msg": "error_frequently_access", "data": ""}`))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithRetry(2, 0),
+		WithRetryBudget(0),
+	)
+
+	_, err := client.GetMINInverterHistory(context.Background(), "ABC123456", time.Now(), "UTC")
+	if !IsRateLimited(err) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests (1 + 2 retries), got %d", requests)
+	}
+}
+
+func TestGetMINInverterHistory_EmptyResponseRetry(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			return // empty body
+		}
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithRetry(2, 0),
+	)
+
+	data, err := client.GetMINInverterHistory(context.Background(), "ABC123456", time.Now(), "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Powers) == 0 {
+		t.Error("expected power data after retry")
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests (1 + 1 retry), got %d", requests)
+	}
+}
+
+func TestParseMINHistory(t *testing.T) {
+	var resp Response[MINHistoryResponse]
+	if err := json.Unmarshal(loadTestData(t, "min_history.json"), &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	loc, err := time.LoadLocation("US/Central")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	date := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	parsed, err := ParseMINHistory(resp.Data.Datas, date, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The malformed time is skipped
+	if len(parsed) != 3 {
+		t.Fatalf("expected 3 parsed points, got %d", len(parsed))
+	}
+
+	// Sorted by time
+	if parsed[0].Time != "06:00" || parsed[1].Time != "12:00" || parsed[2].Time != "12:05" {
+		t.Errorf("unexpected order: %s, %s, %s", parsed[0].Time, parsed[1].Time, parsed[2].Time)
+	}
+
+	if parsed[2].Hour != 12 || parsed[2].Minute != 5 {
+		t.Errorf("expected hour 12, minute 5, got hour %d, minute %d", parsed[2].Hour, parsed[2].Minute)
+	}
+
+	if parsed[1].Power != 4523.5 {
+		t.Errorf("expected Pac 4523.5, got %f", parsed[1].Power)
+	}
+
+	if parsed[0].Date.Location() != loc || parsed[0].Date.Format("2006-01-02") != "2025-02-03" {
+		t.Errorf("expected date 2025-02-03 in %s, got %v", loc, parsed[0].Date)
+	}
+
+	// Select PV power instead
+	parsed, err = ParseMINHistoryField(resp.Data.Datas, date, nil, MINPowerPpv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed[1].Power != 4700 {
+		t.Errorf("expected Ppv 4700, got %f", parsed[1].Power)
+	}
+	if parsed[0].Date.Location() != time.UTC {
+		t.Errorf("expected UTC location for nil loc, got %v", parsed[0].Date.Location())
+	}
+
+	// Unknown field
+	if _, err := ParseMINHistoryField(resp.Data.Datas, date, nil, "bogus"); err == nil {
+		t.Error("expected error for unknown power field")
+	}
+}
+
+func TestGetMINInverterHistoryRange_Progress(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"count": 0, "datas": []}}`))
+	})
+	defer server.Close()
+
+	var calls []string
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithProgress(func(done, total int, date time.Time) {
+			if total != 3 {
+				t.Errorf("expected total 3, got %d", total)
+			}
+			calls = append(calls, fmt.Sprintf("%d %s", done, date.Format("2006-01-02")))
+		}),
+	)
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	if _, err := client.GetMINInverterHistoryRange(context.Background(), "ABC123456", from, to, "UTC"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"1 2025-02-01", "2 2025-02-02", "3 2025-02-03"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %d progress calls, got %d", len(expected), len(calls))
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %q, got %q", i, expected[i], calls[i])
+		}
+	}
+}
+
+func TestWithMINHistoryEndpoint(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/device/tlx/tlx_data_v2" {
+			t.Errorf("expected path /device/tlx/tlx_data_v2, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithMINHistoryEndpoint("device/tlx/tlx_data_v2"),
+	)
+
+	data, err := client.GetMINInverterHistory(context.Background(), "ABC123456", time.Now(), "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Powers) == 0 {
+		t.Error("expected power data from configured endpoint")
+	}
+}
+
+func TestValidateDeviceSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		serial  string
+		wantErr bool
+	}{
+		{name: "typical serial", serial: "ABC1234567"},
+		{name: "lowercase", serial: "bqe4a1b2c3"},
+		{name: "letters only", serial: "ABCDEFGH"},
+		{name: "long serial", serial: "NTCA12345678901234"},
+		{name: "empty", serial: "", wantErr: true},
+		{name: "plant id", serial: "1234567", wantErr: true},
+		{name: "too short", serial: "AB12", wantErr: true},
+		{name: "too long", serial: "ABCDEFGHIJ1234567890X", wantErr: true},
+		{name: "whitespace", serial: "ABC 12345", wantErr: true},
+		{name: "punctuation", serial: "ABC-12345", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceSN(tt.serial)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDeviceSN) {
+					t.Errorf("expected ErrInvalidDeviceSN, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetMINInverterHistory_InvalidSerial(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	_, err := client.GetMINInverterHistory(context.Background(), "12345", time.Now(), "UTC")
+	if !errors.Is(err, ErrInvalidDeviceSN) {
+		t.Errorf("expected ErrInvalidDeviceSN, got %v", err)
+	}
+
+	_, err = client.GetMINInverterDetails(context.Background(), "12345")
+	if !errors.Is(err, ErrInvalidDeviceSN) {
+		t.Errorf("expected ErrInvalidDeviceSN, got %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("expected no requests for invalid serial, got %d", requests)
+	}
+}
+
+func TestMINHistoryDataPoint_Field(t *testing.T) {
+	var resp Response[MINHistoryResponse]
+	if err := json.Unmarshal(loadTestData(t, "min_history.json"), &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	p := resp.Data.Datas[0]
+
+	tests := []struct {
+		field    string
+		expected float64
+	}{
+		{"pac", 4410.5},
+		{"ppv", 4600.0},
+		{"vpv2", 375.4},
+		{"iac1", 18.4},
+		{"temperature", 41.2},
+		{"pf", 0.97},
+		{"qac", -512.3},
+	}
+
+	for _, tt := range tests {
+		got, err := p.Field(tt.field)
+		if err != nil {
+			t.Fatalf("field %q: unexpected error: %v", tt.field, err)
+		}
+		if got != tt.expected {
+			t.Errorf("field %q: expected %f, got %f", tt.field, tt.expected, got)
+		}
+	}
+
+	if _, err := p.Field("bogus"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	if fields := MINHistoryFields(); len(fields) != 11 || fields[0] != "iac1" {
+		t.Errorf("unexpected field list: %v", fields)
+	}
+}
+
+func TestGetMINInverterHistoryDay(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	day, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if day.Date != "2025-02-03" || len(day.Points) != 4 {
+		t.Fatalf("unexpected day: %s with %d points", day.Date, len(day.Points))
+	}
+
+	// Points are sorted by time of day
+	if day.Points[0].ClockTime() != "06:00" || day.Points[2].ClockTime() != "12:05" {
+		t.Errorf("unexpected order: %v", day.Points)
+	}
+	if day.Points[0].Temperature.Float64() != 18.5 {
+		t.Errorf("expected temperature 18.5, got %f", day.Points[0].Temperature.Float64())
+	}
+
+	pd := day.PowerData()
+	if pd.PlantID.String() != "ABC123456" || pd.Powers[2].Power != 4410.5 {
+		t.Errorf("unexpected power data: %+v", pd)
+	}
+}
+
+func TestGetDataloggerSignal(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/device/datalogger/signal" {
+			t.Errorf("expected path /device/datalogger/signal, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("datalog_sn") != "DLG1234567" {
+			t.Errorf("expected datalog_sn DLG1234567, got %q", r.URL.Query().Get("datalog_sn"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "datalogger_signal.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	points, err := client.GetDataloggerSignal(context.Background(), "DLG1234567")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []SignalPoint{
+		{Time: "2025-02-03 12:00:00", Strength: -65},
+		{Time: "2025-02-03 12:05:00", Strength: -68},
+		{Time: "2025-02-03 12:10:00", Strength: -71},
+		{Time: "2025-02-03 12:15:00", Strength: -90},
+	}
+	if len(points) != len(expected) {
+		t.Fatalf("expected %d points, got %d", len(expected), len(points))
+	}
+	for i := range expected {
+		if points[i] != expected[i] {
+			t.Errorf("point %d: expected %+v, got %+v", i, expected[i], points[i])
+		}
+	}
+
+	if _, err := client.GetDataloggerSignal(context.Background(), "bad sn"); !errors.Is(err, ErrInvalidDeviceSN) {
+		t.Errorf("expected ErrInvalidDeviceSN, got %v", err)
+	}
+}
+
+func TestEachMINInverterHistoryRange(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	batch, err := client.GetMINInverterHistoryRange(context.Background(), "ABC123456", from, to, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var streamed []PowerData
+	err = client.EachMINInverterHistoryRange(context.Background(), "ABC123456", from, to, "UTC", func(data *PowerData) error {
+		streamed = append(streamed, *data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(streamed, batch) {
+		t.Errorf("streamed days differ from batch result")
+	}
+	if len(streamed) != 3 {
+		t.Errorf("expected 3 days, got %d", len(streamed))
+	}
+}
+
+func TestEachMINInverterHistoryDay_CallbackError(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+
+	stop := errors.New("stop")
+	err := client.EachMINInverterHistoryDay(context.Background(), "ABC123456", from, to, "UTC", func(day *MINHistoryDay) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected fetching to stop after 1 request, got %d", requests)
+	}
+}
+
+func TestListDevicesRaw(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "device_list.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	devices, raw, err := client.ListDevicesRaw(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(devices) != 1 || devices[0].DeviceSN.String() != "ABC123456" {
+		t.Errorf("unexpected devices: %+v", devices)
+	}
+	if len(raw) == 0 {
+		t.Fatal("expected non-empty raw data")
+	}
+
+	var data struct {
+		Devices []map[string]any `json:"devices"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("raw data is not valid JSON: %v", err)
+	}
+	if len(data.Devices) != 1 || data.Devices[0]["last_update"] != "2025-02-03 12:30:00" {
+		t.Errorf("expected raw device fields, got %v", data.Devices)
+	}
+}
+
+func TestGetMINInverterDetails_PartialFields(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_inverter_partial.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	data, err := client.GetMINInverterDetails(context.Background(), "ABC123456")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// vpv1 is a genuine zero reading and must be reported present
+	for _, field := range []string{"tlx_sn", "pac", "vpv1", "ipv1", "vac1"} {
+		if !data.Has(field) {
+			t.Errorf("expected %s to be present", field)
+		}
+	}
+	for _, field := range []string{"vpv2", "ipv2", "temperature", "fac", "pf", "model"} {
+		if data.Has(field) {
+			t.Errorf("expected %s to be absent", field)
+		}
+	}
+
+	if data.Pac.Float64() != 2150.0 {
+		t.Errorf("expected pac 2150, got %v", data.Pac.Float64())
+	}
+}
+
+func TestGetMINInverterDetails_AllFieldsPresent(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_inverter.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	data, err := client.GetMINInverterDetails(context.Background(), "ABC123456")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, field := range []string{"vpv2", "ipv2", "temperature", "rated_power"} {
+		if !data.Has(field) {
+			t.Errorf("expected %s to be present", field)
+		}
+	}
 }
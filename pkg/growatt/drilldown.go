@@ -0,0 +1,107 @@
+package growatt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EnergyDrillDown fetches plant energy lazily from yearly totals down to
+// monthly and daily values. Each level is requested only when asked for and
+// cached, so exploring a multi-year history costs one request per level visited.
+type EnergyDrillDown struct {
+	client  *Client
+	plantID string
+
+	mu       sync.Mutex
+	cache    map[string]*EnergyData
+	inFlight map[string]*drillCall
+}
+
+// drillCall is a fetch in progress, shared by callers asking for the same key
+type drillCall struct {
+	done chan struct{}
+	data *EnergyData
+	err  error
+}
+
+// NewEnergyDrillDown creates a drill-down over one plant's energy history
+func (c *Client) NewEnergyDrillDown(plantID string) *EnergyDrillDown {
+	return &EnergyDrillDown{
+		client:   c,
+		plantID:  plantID,
+		cache:    make(map[string]*EnergyData),
+		inFlight: make(map[string]*drillCall),
+	}
+}
+
+// Years returns yearly energy totals for fromYear through toYear
+func (d *EnergyDrillDown) Years(ctx context.Context, fromYear, toYear int) (*EnergyData, error) {
+	start := fmt.Sprintf("%04d-01-01", fromYear)
+	end := fmt.Sprintf("%04d-12-31", toYear)
+	return d.fetch(ctx, TimeUnitYear, start, end, func() (*EnergyData, error) {
+		return d.client.GetPlantEnergy(ctx, d.plantID, start, end, TimeUnitYear)
+	})
+}
+
+// Months returns monthly energy totals for one year
+func (d *EnergyDrillDown) Months(ctx context.Context, year int) (*EnergyData, error) {
+	start := fmt.Sprintf("%04d-01-01", year)
+	end := fmt.Sprintf("%04d-12-31", year)
+	return d.fetch(ctx, TimeUnitMonth, start, end, func() (*EnergyData, error) {
+		return d.client.GetPlantEnergy(ctx, d.plantID, start, end, TimeUnitMonth)
+	})
+}
+
+// Days returns daily energy for one month
+func (d *EnergyDrillDown) Days(ctx context.Context, year int, month time.Month) (*EnergyData, error) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	start, end := first.Format("2006-01-02"), last.Format("2006-01-02")
+	return d.fetch(ctx, TimeUnitDay, start, end, func() (*EnergyData, error) {
+		return d.client.GetPlantEnergyRange(ctx, d.plantID, first, last)
+	})
+}
+
+// fetch returns the cached result for a unit and range, calling get on a miss.
+// The lock is not held while get runs; concurrent callers for the same key
+// wait for the fetch already in flight. Failed fetches are not cached.
+func (d *EnergyDrillDown) fetch(ctx context.Context, unit TimeUnit, start, end string, get func() (*EnergyData, error)) (*EnergyData, error) {
+	key := string(unit) + ":" + start + ":" + end
+
+	d.mu.Lock()
+	if data, ok := d.cache[key]; ok {
+		d.mu.Unlock()
+		return data, nil
+	}
+	if call, ok := d.inFlight[key]; ok {
+		d.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.data, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &drillCall{done: make(chan struct{})}
+	d.inFlight[key] = call
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		if call.err == nil {
+			d.cache[key] = call.data
+		}
+		delete(d.inFlight, key)
+		d.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.data, call.err = get()
+	if call.err != nil {
+		call.data = nil
+		call.err = fmt.Errorf("fetching %s energy for %s to %s: %w", unit, start, end, call.err)
+	}
+	return call.data, call.err
+}
@@ -0,0 +1,173 @@
+package growatt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnergyDrillDown(t *testing.T) {
+	var calls []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plant/energy" {
+			t.Errorf("expected path /plant/energy, got %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		call := fmt.Sprintf("%s %s %s", q.Get("time_unit"), q.Get("start_date"), q.Get("end_date"))
+		calls = append(calls, call)
+
+		var datas string
+		switch q.Get("time_unit") {
+		case "year":
+			datas = `{"2023": 5200.5, "2024": 5480.0}`
+		case "month":
+			datas = `{"2024-01": 210.0, "2024-02": 260.5}`
+		default:
+			datas = fmt.Sprintf(`{"%s": 12.5}`, q.Get("start_date"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"error_code": 0, "error_msg": "success", "data": {"plant_id": "12345", "datas": %s}}`, datas)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	drill := client.NewEnergyDrillDown("12345")
+	ctx := context.Background()
+
+	years, err := drill.Years(ctx, 2023, 2024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(years.Datas) != 2 || years.Datas[1].Date != "2024" {
+		t.Errorf("unexpected yearly data: %+v", years.Datas)
+	}
+
+	months, err := drill.Months(ctx, 2024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(months.Datas) != 2 || months.Datas[0].Date != "2024-01" {
+		t.Errorf("unexpected monthly data: %+v", months.Datas)
+	}
+
+	// February 2024 has 29 days, fetched in chunks of MaxEnergyDayRange
+	days, err := drill.Days(ctx, 2024, time.February)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days.Datas) != 5 || days.Datas[0].Date != "2024-02-01" {
+		t.Errorf("unexpected daily data: %+v", days.Datas)
+	}
+
+	// Revisiting cached levels makes no further requests
+	if _, err := drill.Years(ctx, 2023, 2024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := drill.Months(ctx, 2024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := drill.Days(ctx, 2024, time.February); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"year 2023-01-01 2024-12-31",
+		"month 2024-01-01 2024-12-31",
+		"day 2024-02-01 2024-02-07",
+		"day 2024-02-08 2024-02-14",
+		"day 2024-02-15 2024-02-21",
+		"day 2024-02-22 2024-02-28",
+		"day 2024-02-29 2024-02-29",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %q, got %q", i, expected[i], calls[i])
+		}
+	}
+}
+
+func TestEnergyDrillDown_ErrorNotCached(t *testing.T) {
+	var requests int
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			w.Write([]byte(`{"error_code": 10012, "error_msg": "error_permission_denied"}`))
+			return
+		}
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"plant_id": "12345", "datas": {"2024-01": 210.0}}}`))
+	})
+	defer server.Close()
+
+	drill := newTestClient(t, server).NewEnergyDrillDown("12345")
+
+	if _, err := drill.Months(context.Background(), 2024); err == nil {
+		t.Fatal("expected error on first fetch")
+	}
+	if _, err := drill.Months(context.Background(), 2024); err != nil {
+		t.Fatalf("expected retry after error to succeed, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestEnergyDrillDown_FetchDoesNotBlockOtherKeys(t *testing.T) {
+	drill := NewClient("test-token").NewEnergyDrillDown("12345")
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var slowCalls int32
+	slow := func() (*EnergyData, error) {
+		if atomic.AddInt32(&slowCalls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return &EnergyData{}, nil
+	}
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := drill.fetch(ctx, TimeUnitMonth, "2024-01-01", "2024-12-31", slow)
+			errs <- err
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+
+	// Another key completes while the slow fetch is still in flight
+	done := make(chan error, 1)
+	go func() {
+		_, err := drill.fetch(ctx, TimeUnitYear, "2023-01-01", "2024-12-31", func() (*EnergyData, error) {
+			return &EnergyData{}, nil
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("fetch for another key blocked behind the slow fetch")
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&slowCalls); n != 1 {
+		t.Errorf("expected callers for the same key to share one fetch, got %d", n)
+	}
+}
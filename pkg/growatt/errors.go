@@ -1,9 +1,14 @@
 package growatt
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"strings"
+	"syscall"
 )
 
 // APIError represents an error returned by the Growatt API
@@ -16,6 +21,33 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("growatt api error %d: %s", e.Code, e.Message)
 }
 
+// Is reports whether e has the same code as target, so errors.Is matches
+// the exported API errors against errors returned by the client. Codes 10011
+// and 10012 each cover two errors, which are told apart by their messages: a
+// 10011 naming the token is an invalid token, any other is permission denied.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok || e.Code != t.Code {
+		return false
+	}
+	switch e.Code {
+	case 10011:
+		return isInvalidTokenMessage(e.Message) == isInvalidTokenMessage(t.Message)
+	case 10012:
+		return isRateLimitMessage(e.Message) == isRateLimitMessage(t.Message)
+	}
+	return true
+}
+
+// HTTPError is a server-side HTTP failure (5xx) from the API
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("growatt api http error %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
 // Common API errors
 var (
 	ErrPermissionDenied = &APIError{Code: 10011, Message: "permission denied"}
@@ -24,18 +56,41 @@ var (
 	ErrInvalidToken     = &APIError{Code: 10011, Message: "invalid token"}
 )
 
+// KnownAPIErrors lists the API errors for every documented error code, for
+// handling them exhaustively
+var KnownAPIErrors = []*APIError{
+	ErrPermissionDenied,
+	ErrInvalidToken,
+	ErrPlantNotFound,
+	ErrFrequentAccess,
+}
+
 // Client errors
 var (
-	ErrNoToken       = errors.New("no API token provided")
-	ErrInvalidDate   = errors.New("invalid date format")
-	ErrEmptyResponse = errors.New("empty response from API")
+	ErrNoToken         = errors.New("no API token provided")
+	ErrInvalidDate     = errors.New("invalid date format")
+	ErrEmptyResponse   = errors.New("empty response from API")
+	ErrInvalidDeviceSN = errors.New("invalid device serial number")
+
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+	ErrCountMismatch        = errors.New("response count does not match items")
+	ErrNoStorage            = errors.New("plant has no storage")
 )
 
 // IsPermissionDenied checks if the error is a permission denied error
 func IsPermissionDenied(err error) bool {
 	var apiErr *APIError
 	if errors.As(err, &apiErr) {
-		return apiErr.Code == 10011
+		return apiErr.Code == 10011 && !isInvalidTokenMessage(apiErr.Message)
+	}
+	return false
+}
+
+// IsInvalidToken checks if the error is an invalid token error
+func IsInvalidToken(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 10011 && isInvalidTokenMessage(apiErr.Message)
 	}
 	return false
 }
@@ -53,13 +108,70 @@ func IsPlantNotFound(err error) bool {
 func IsRateLimited(err error) bool {
 	var apiErr *APIError
 	if errors.As(err, &apiErr) {
-		return apiErr.Code == 10012 && (apiErr.Message == "error_frequently_access" ||
-			strings.Contains(apiErr.Message, "frequently"))
+		return apiErr.Code == 10012 && isRateLimitMessage(apiErr.Message)
 	}
 	return false
 }
 
-// NewAPIError creates a new API error from code and message
+// isRateLimitMessage reports whether a 10012 message means rate limiting
+// rather than an unknown plant
+func isRateLimitMessage(message string) bool {
+	return message == "error_frequently_access" || strings.Contains(message, "frequently")
+}
+
+// isInvalidTokenMessage reports whether a 10011 message means the token
+// itself was rejected rather than access to a resource being denied
+func isInvalidTokenMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "token") && !strings.Contains(message, "permission")
+}
+
+// errorDescriptions maps known API error codes to human-readable descriptions
+var errorDescriptions = map[int]string{
+	0:     "success",
+	10011: "permission denied: invalid token or insufficient permissions",
+	10012: "plant not found, or requests too frequent (rate limited)",
+}
+
+// DescribeError returns a human-readable description of an API error code
+func DescribeError(code int) string {
+	if desc, ok := errorDescriptions[code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("unknown error code %d", code)
+}
+
+// IsRetryable reports whether err is transient and the request may succeed
+// if repeated: rate limits, 5xx responses, empty bodies, timeouts and dropped
+// connections. API errors such as permission denied, invalid input and
+// cancelled or expired contexts are fatal.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if IsRateLimited(err) || errors.Is(err, ErrEmptyResponse) {
+		return true
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// NewAPIError creates a new API error from code and message, describing
+// the code when the API gives no message
 func NewAPIError(code int, message string) *APIError {
+	if message == "" {
+		message = DescribeError(code)
+	}
 	return &APIError{Code: code, Message: message}
 }
@@ -1,7 +1,13 @@
 package growatt
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
 	"testing"
 )
 
@@ -92,3 +98,174 @@ func TestIsPlantNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected string
+	}{
+		{0, "success"},
+		{10011, "permission denied: invalid token or insufficient permissions"},
+		{10012, "plant not found, or requests too frequent (rate limited)"},
+		{99999, "unknown error code 99999"},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeError(tt.code); got != tt.expected {
+			t.Errorf("code %d: expected %q, got %q", tt.code, tt.expected, got)
+		}
+	}
+}
+
+func TestNewAPIError_EmptyMessage(t *testing.T) {
+	err := NewAPIError(10011, "")
+	if err.Message != DescribeError(10011) {
+		t.Errorf("expected description as message, got %q", err.Message)
+	}
+	if !IsPermissionDenied(err) {
+		t.Error("expected permission denied error")
+	}
+
+	// An empty 10012 is not mistaken for a rate limit
+	if IsRateLimited(NewAPIError(10012, "")) {
+		t.Error("expected empty 10012 not to be treated as rate limited")
+	}
+
+	// API messages are kept as given
+	if msg := NewAPIError(10012, "error_frequently_access").Message; msg != "error_frequently_access" {
+		t.Errorf("expected API message to be kept, got %q", msg)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", NewAPIError(10012, "error_frequently_access"), true},
+		{"wrapped rate limit", fmt.Errorf("fetching: %w", NewAPIError(10012, "error_frequently_access")), true},
+		{"empty response", ErrEmptyResponse, true},
+		{"server error", &HTTPError{StatusCode: 502}, true},
+		{"connection reset", fmt.Errorf("executing request: %w", syscall.ECONNRESET), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"permission denied", ErrPermissionDenied, false},
+		{"plant not found", NewAPIError(10012, "error_plant_not_found"), false},
+		{"invalid date", ErrInvalidDate, false},
+		{"invalid serial", ErrInvalidDeviceSN, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("executing request: %w", context.DeadlineExceeded), false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorFromServer(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	_, err := newTestClient(t, server).ListPlants(context.Background())
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected HTTPError 503, got %v", err)
+	}
+	if !IsRetryable(err) {
+		t.Error("expected 503 to be retryable")
+	}
+}
+
+func TestKnownAPIErrors(t *testing.T) {
+	predicates := map[string]func(error) bool{
+		"IsPermissionDenied": IsPermissionDenied,
+		"IsInvalidToken":     IsInvalidToken,
+		"IsPlantNotFound":    IsPlantNotFound,
+		"IsRateLimited":      IsRateLimited,
+	}
+
+	tests := []struct {
+		err       *APIError
+		message   string
+		predicate string
+	}{
+		{ErrPermissionDenied, "error_permission_denied", "IsPermissionDenied"},
+		{ErrInvalidToken, "error_invalid_token", "IsInvalidToken"},
+		{ErrPlantNotFound, "error_plant_not_found", "IsPlantNotFound"},
+		{ErrFrequentAccess, "error_frequently_access", "IsRateLimited"},
+	}
+
+	if len(tests) != len(KnownAPIErrors) {
+		t.Fatalf("expected a test for each of the %d known errors, got %d", len(KnownAPIErrors), len(tests))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Message, func(t *testing.T) {
+			got := NewAPIError(tt.err.Code, tt.message)
+			// Exactly one predicate matches each error, as returned by the
+			// API and as the exported value itself
+			for name, predicate := range predicates {
+				for _, err := range []error{got, tt.err} {
+					if matched := predicate(err); matched != (name == tt.predicate) {
+						t.Errorf("%s(%v) = %v", name, err, matched)
+					}
+				}
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("expected errors.Is(%v, %v)", got, tt.err)
+			}
+			if wrapped := fmt.Errorf("fetching: %w", got); !errors.Is(wrapped, tt.err) {
+				t.Errorf("expected errors.Is to see through wrapping")
+			}
+		})
+	}
+}
+
+func TestAPIErrorIs_InvalidTokenVersusPermissionDenied(t *testing.T) {
+	denied := NewAPIError(10011, "error_permission_denied")
+	badToken := NewAPIError(10011, "error_invalid_token")
+	described := NewAPIError(10011, "")
+
+	if errors.Is(denied, ErrInvalidToken) {
+		t.Error("permission denied should not match ErrInvalidToken")
+	}
+	if errors.Is(badToken, ErrPermissionDenied) {
+		t.Error("invalid token should not match ErrPermissionDenied")
+	}
+	if !errors.Is(described, ErrPermissionDenied) || errors.Is(described, ErrInvalidToken) {
+		t.Error("a 10011 without a message should match ErrPermissionDenied only")
+	}
+	if IsPermissionDenied(badToken) || !IsInvalidToken(badToken) {
+		t.Error("an invalid token should match IsInvalidToken only")
+	}
+	if !IsPermissionDenied(described) || IsInvalidToken(described) {
+		t.Error("a 10011 without a message should match IsPermissionDenied only")
+	}
+}
+
+func TestAPIErrorIs_RateLimitVersusNotFound(t *testing.T) {
+	notFound := NewAPIError(10012, "error_plant_not_found")
+	limited := NewAPIError(10012, "error_frequently_access")
+
+	if errors.Is(notFound, ErrFrequentAccess) {
+		t.Error("plant not found should not match ErrFrequentAccess")
+	}
+	if errors.Is(limited, ErrPlantNotFound) {
+		t.Error("rate limit should not match ErrPlantNotFound")
+	}
+	if errors.Is(notFound, ErrPermissionDenied) {
+		t.Error("different codes should not match")
+	}
+	if errors.Is(errors.New("plant not found"), ErrPlantNotFound) {
+		t.Error("non-API errors should not match")
+	}
+}
@@ -0,0 +1,84 @@
+// Package growatttest provides a Growatt client backed by fixed responses
+// for testing code built on package growatt.
+package growatttest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogrowatt/pkg/growatt"
+)
+
+// BaseURL is the base URL of clients returned by NewClient. Requests to it
+// never leave the process.
+const BaseURL = "http://growatttest.invalid/"
+
+// Responses maps endpoint paths such as "plant/list" to JSON response bodies
+type Responses map[string]string
+
+// Transport is an http.RoundTripper answering requests from Responses.
+// Requests for endpoints without a response fail the test.
+type Transport struct {
+	t         testing.TB
+	responses Responses
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// NewTransport returns a Transport serving responses
+func NewTransport(t testing.TB, responses Responses) *Transport {
+	return &Transport{t: t, responses: responses}
+}
+
+// RoundTrip returns the stubbed response for the request's endpoint
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	endpoint := strings.TrimPrefix(req.URL.Path, "/")
+
+	tr.mu.Lock()
+	tr.requests = append(tr.requests, endpoint)
+	tr.mu.Unlock()
+
+	status := http.StatusOK
+	body, ok := tr.responses[endpoint]
+	if !ok {
+		tr.t.Errorf("growatttest: no response stubbed for %s", endpoint)
+		status = http.StatusNotFound
+		body = "no response stubbed for " + endpoint
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns the endpoints requested so far, in order
+func (tr *Transport) Requests() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]string(nil), tr.requests...)
+}
+
+// NewClient returns a client whose requests are answered from responses.
+// Rate limiting is disabled; opts are applied after the test defaults.
+func NewClient(t testing.TB, responses Responses, opts ...growatt.ClientOption) (*growatt.Client, *Transport) {
+	t.Helper()
+	tr := NewTransport(t, responses)
+	defaults := []growatt.ClientOption{
+		growatt.WithBaseURL(BaseURL),
+		growatt.WithHTTPClient(&http.Client{Transport: tr}),
+		growatt.WithRateLimit(0),
+	}
+	return growatt.NewClient("test-token", append(defaults, opts...)...), tr
+}
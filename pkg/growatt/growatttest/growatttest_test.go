@@ -0,0 +1,49 @@
+package growatttest
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestNewClient_StubsPlantAndDeviceList(t *testing.T) {
+	client, tr := NewClient(t, Responses{
+		"plant/list": `{"error_code": 0, "error_msg": "success", "data": {"count": 1, "plants": [
+			{"plant_id": "12345", "plant_name": "Home Solar", "current_power": 4523.5}
+		]}}`,
+		"device/list": `{"error_code": 0, "error_msg": "success", "data": {"count": 1, "devices": [
+			{"device_sn": "ABC123456", "device_type": 7, "device_name": "MIN 9000TL-X"}
+		]}}`,
+	})
+	ctx := context.Background()
+
+	plants, err := client.ListPlants(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plants) != 1 || plants[0].PlantID != "12345" || plants[0].PlantName != "Home Solar" {
+		t.Errorf("unexpected plants: %+v", plants)
+	}
+
+	devices, err := client.ListDevices(ctx, "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(devices) != 1 || devices[0].DeviceSN != "ABC123456" {
+		t.Errorf("unexpected devices: %+v", devices)
+	}
+
+	if got := tr.Requests(); !slices.Equal(got, []string{"plant/list", "device/list"}) {
+		t.Errorf("unexpected requests: %v", got)
+	}
+}
+
+func TestNewClient_APIError(t *testing.T) {
+	client, _ := NewClient(t, Responses{
+		"plant/details": `{"error_code": 10012, "error_msg": "error_plant_not_found", "data": ""}`,
+	})
+
+	if _, err := client.GetPlantDetails(context.Background(), "999"); err == nil {
+		t.Error("expected the stubbed API error")
+	}
+}
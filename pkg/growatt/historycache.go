@@ -0,0 +1,62 @@
+package growatt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// historyCachePath returns the cache file for one serial and date
+func (c *Client) historyCachePath(serial, date string) string {
+	return filepath.Join(c.historyCacheDir, fmt.Sprintf("%s_%s.json", serial, date))
+}
+
+// historyCacheable reports whether a day is complete and can be served from the cache.
+// Days on or after today in the device's timezone may still change.
+func (c *Client) historyCacheable(date, timezone string) bool {
+	if c.historyCacheDir == "" {
+		return false
+	}
+	loc, err := ResolveTimezone(timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return date < time.Now().In(loc).Format("2006-01-02")
+}
+
+// readHistoryCache returns cached points, or ok=false on a cache miss
+func (c *Client) readHistoryCache(serial, date string) (points []MINHistoryDataPoint, ok bool, err error) {
+	data, err := os.ReadFile(c.historyCachePath(serial, date))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading history cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, false, fmt.Errorf("parsing history cache: %w", err)
+	}
+	return points, true, nil
+}
+
+// writeHistoryCache stores a completed day's points
+func (c *Client) writeHistoryCache(serial, date string, points []MINHistoryDataPoint) error {
+	if err := os.MkdirAll(c.historyCacheDir, 0755); err != nil {
+		return fmt.Errorf("creating history cache: %w", err)
+	}
+
+	data, err := json.Marshal(points)
+	if err != nil {
+		return fmt.Errorf("encoding history cache: %w", err)
+	}
+
+	if err := os.WriteFile(c.historyCachePath(serial, date), data, 0644); err != nil {
+		return fmt.Errorf("writing history cache: %w", err)
+	}
+	return nil
+}
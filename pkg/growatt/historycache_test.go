@@ -0,0 +1,128 @@
+package growatt
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newHistoryCacheTest(t *testing.T) (*Client, *int, string) {
+	t.Helper()
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "min_history.json"))
+	})
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithHistoryCache(dir),
+	)
+	return client, &requests, dir
+}
+
+func TestHistoryCache_Miss(t *testing.T) {
+	client, requests, dir := newHistoryCacheTest(t)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+
+	first, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", yesterday, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "ABC123456_"+yesterday.Format("2006-01-02")+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected cache file %s: %v", path, err)
+	}
+
+	// Second fetch is served from disk
+	second, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", yesterday, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("expected 1 request, got %d", *requests)
+	}
+	if len(second.Points) != len(first.Points) || second.Points[0].Pac != first.Points[0].Pac {
+		t.Errorf("cached day differs: %+v vs %+v", second.Points, first.Points)
+	}
+}
+
+func TestHistoryCache_Hit(t *testing.T) {
+	client, requests, dir := newHistoryCacheTest(t)
+	date := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	cached := `[{"time": "2025-02-03 12:00:00", "pac": 1234.5}]`
+	if err := os.WriteFile(filepath.Join(dir, "ABC123456_2025-02-03.json"), []byte(cached), 0644); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	day, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", date, "UTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *requests != 0 {
+		t.Errorf("expected no requests on cache hit, got %d", *requests)
+	}
+	if len(day.Points) != 1 || day.Points[0].Pac.Float64() != 1234.5 {
+		t.Errorf("unexpected cached points: %+v", day.Points)
+	}
+}
+
+func TestHistoryCache_TodayBypass(t *testing.T) {
+	client, requests, dir := newHistoryCacheTest(t)
+	today := time.Now().UTC()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", today, "UTC"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if *requests != 2 {
+		t.Errorf("expected today to be fetched every time, got %d requests", *requests)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected today not to be cached, found %d files", len(entries))
+	}
+}
+
+func TestHistoryCache_EmptyDayNotCached(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "data": {"datas": []}}`))
+	})
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0), WithHistoryCache(dir))
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+
+	for i := 0; i < 2; i++ {
+		day, err := client.GetMINInverterHistoryDay(context.Background(), "ABC123456", yesterday, "UTC")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(day.Points) != 0 {
+			t.Fatalf("expected no points, got %d", len(day.Points))
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected an empty day to be refetched, got %d requests", requests)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected an empty day not to be cached, found %d files", len(entries))
+	}
+}
@@ -0,0 +1,56 @@
+package growatt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MergeMode controls how points sharing the same time are combined
+type MergeMode string
+
+const (
+	MergeSum     MergeMode = "sum"
+	MergeReplace MergeMode = "replace"
+)
+
+// valid reports whether m is one of the defined merge modes. The zero value
+// is valid and means MergeReplace.
+func (m MergeMode) valid() bool {
+	return m == "" || m == MergeSum || m == MergeReplace
+}
+
+// MergePowerSeries merges power series by normalized time and returns sorted output.
+// With MergeSum, powers at the same time are added together; with MergeReplace
+// (or an empty mode), later series overwrite earlier ones. Any other mode is an error.
+func MergePowerSeries(mode MergeMode, series ...[]PowerDataPoint) ([]PowerDataPoint, error) {
+	if !mode.valid() {
+		return nil, fmt.Errorf("unknown merge mode %q", mode)
+	}
+
+	merged := make(map[string]float64)
+	for _, s := range series {
+		for _, p := range s {
+			timeStr := normalizeTime(p.Time)
+			if mode == MergeSum {
+				merged[timeStr] += p.Power
+			} else {
+				merged[timeStr] = p.Power
+			}
+		}
+	}
+
+	result := make([]PowerDataPoint, 0, len(merged))
+	for timeStr, power := range merged {
+		result = append(result, PowerDataPoint{
+			Time:  timeStr,
+			Power: power,
+		})
+	}
+
+	// Sort by time
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time < result[j].Time
+	})
+
+	return result, nil
+}
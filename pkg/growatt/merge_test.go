@@ -0,0 +1,117 @@
+package growatt
+
+import (
+	"testing"
+)
+
+func TestMergePowerSeries(t *testing.T) {
+	a := []PowerDataPoint{
+		{Time: "10:05", Power: 200},
+		{Time: "10:00", Power: 100},
+	}
+	b := []PowerDataPoint{
+		{Time: "2025-02-03 10:05:00", Power: 50},
+		{Time: "10:10", Power: 300},
+	}
+
+	tests := []struct {
+		name     string
+		mode     MergeMode
+		series   [][]PowerDataPoint
+		expected []PowerDataPoint
+	}{
+		{
+			name:     "no series",
+			mode:     MergeSum,
+			series:   nil,
+			expected: []PowerDataPoint{},
+		},
+		{
+			name:   "single series is sorted",
+			mode:   MergeSum,
+			series: [][]PowerDataPoint{a},
+			expected: []PowerDataPoint{
+				{Time: "10:00", Power: 100},
+				{Time: "10:05", Power: 200},
+			},
+		},
+		{
+			name:   "overlapping sum",
+			mode:   MergeSum,
+			series: [][]PowerDataPoint{a, b},
+			expected: []PowerDataPoint{
+				{Time: "10:00", Power: 100},
+				{Time: "10:05", Power: 250},
+				{Time: "10:10", Power: 300},
+			},
+		},
+		{
+			name:   "overlapping replace",
+			mode:   MergeReplace,
+			series: [][]PowerDataPoint{a, b},
+			expected: []PowerDataPoint{
+				{Time: "10:00", Power: 100},
+				{Time: "10:05", Power: 50},
+				{Time: "10:10", Power: 300},
+			},
+		},
+		{
+			name: "disjoint series",
+			mode: MergeSum,
+			series: [][]PowerDataPoint{
+				{{Time: "12:00", Power: 1}},
+				{{Time: "06:00", Power: 2}},
+			},
+			expected: []PowerDataPoint{
+				{Time: "06:00", Power: 2},
+				{Time: "12:00", Power: 1},
+			},
+		},
+		{
+			name: "duplicates within one series",
+			mode: MergeSum,
+			series: [][]PowerDataPoint{
+				{{Time: "12:00", Power: 1}, {Time: "12:00:30", Power: 2}},
+			},
+			expected: []PowerDataPoint{
+				{Time: "12:00", Power: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MergePowerSeries(tt.mode, tt.series...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d points, got %d: %v", len(tt.expected), len(result), result)
+			}
+			for i, p := range result {
+				if p != tt.expected[i] {
+					t.Errorf("point %d: expected %v, got %v", i, tt.expected[i], p)
+				}
+			}
+		})
+	}
+}
+
+func TestMergePowerSeries_Modes(t *testing.T) {
+	series := [][]PowerDataPoint{
+		{{Time: "10:00", Power: 1}},
+		{{Time: "10:00", Power: 2}},
+	}
+
+	result, err := MergePowerSeries("", series...)
+	if err != nil {
+		t.Fatalf("unexpected error for empty mode: %v", err)
+	}
+	if len(result) != 1 || result[0].Power != 2 {
+		t.Errorf("expected empty mode to replace, got %v", result)
+	}
+
+	if _, err := MergePowerSeries("average", series...); err == nil {
+		t.Error("expected error for unknown merge mode")
+	}
+}
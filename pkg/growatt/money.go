@@ -0,0 +1,103 @@
+package growatt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency describes how amounts in a plant's currency are written
+type Currency struct {
+	Code        string // ISO 4217 code, empty if unknown
+	Symbol      string
+	SymbolAfter bool // e.g. "1.234,56 €" rather than "€1,234.56"
+	Thousands   string
+	Decimal     string
+	Decimals    int
+}
+
+// currencies holds the conventions for common currencies, keyed by ISO code
+var currencies = map[string]Currency{
+	"USD": {Code: "USD", Symbol: "$", Thousands: ",", Decimal: ".", Decimals: 2},
+	"AUD": {Code: "AUD", Symbol: "A$", Thousands: ",", Decimal: ".", Decimals: 2},
+	"CAD": {Code: "CAD", Symbol: "C$", Thousands: ",", Decimal: ".", Decimals: 2},
+	"GBP": {Code: "GBP", Symbol: "£", Thousands: ",", Decimal: ".", Decimals: 2},
+	"CNY": {Code: "CNY", Symbol: "¥", Thousands: ",", Decimal: ".", Decimals: 2},
+	"JPY": {Code: "JPY", Symbol: "¥", Thousands: ",", Decimal: ".", Decimals: 0},
+	"INR": {Code: "INR", Symbol: "₹", Thousands: ",", Decimal: ".", Decimals: 2},
+	"EUR": {Code: "EUR", Symbol: "€", SymbolAfter: true, Thousands: ".", Decimal: ",", Decimals: 2},
+	"PLN": {Code: "PLN", Symbol: "zł", SymbolAfter: true, Thousands: " ", Decimal: ",", Decimals: 2},
+	"SEK": {Code: "SEK", Symbol: "kr", SymbolAfter: true, Thousands: " ", Decimal: ",", Decimals: 2},
+	"CHF": {Code: "CHF", Symbol: "CHF", Thousands: "'", Decimal: ".", Decimals: 2},
+}
+
+// currencySymbols maps unambiguous symbols back to their currency
+var currencySymbols = map[string]string{
+	"$":  "USD",
+	"£":  "GBP",
+	"€":  "EUR",
+	"₹":  "INR",
+	"zł": "PLN",
+	"￥":  "CNY",
+	"¥":  "CNY",
+}
+
+// Currency returns the plant's currency, identified from MoneyUnitText or
+// MoneyUnit as either an ISO code or a symbol. Unknown units are written
+// after the amount with plain 1,234.56 separators.
+func (p Plant) Currency() Currency {
+	for _, unit := range []string{p.MoneyUnitText, p.MoneyUnit} {
+		unit = strings.TrimSpace(unit)
+		if c, ok := currencies[strings.ToUpper(unit)]; ok {
+			return c
+		}
+		if code, ok := currencySymbols[unit]; ok {
+			return currencies[code]
+		}
+	}
+
+	symbol := strings.TrimSpace(p.MoneyUnit)
+	if symbol == "" {
+		symbol = strings.TrimSpace(p.MoneyUnitText)
+	}
+	return Currency{Symbol: symbol, SymbolAfter: true, Thousands: ",", Decimal: ".", Decimals: 2}
+}
+
+// FormatMoney formats an amount in the plant's currency, e.g. "$1,234.56"
+// or "1.234,56 €"
+func (p Plant) FormatMoney(amount float64) string {
+	return p.Currency().Format(amount)
+}
+
+// Format writes an amount with the currency's separators and symbol placement
+func (c Currency) Format(amount float64) string {
+	sign := ""
+	if amount < 0 && math.Round(amount*math.Pow10(c.Decimals)) != 0 {
+		sign = "-"
+	}
+	text := strconv.FormatFloat(math.Abs(amount), 'f', c.Decimals, 64)
+
+	whole, frac, _ := strings.Cut(text, ".")
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(c.Thousands)
+		}
+		b.WriteRune(digit)
+	}
+	number := b.String()
+	if frac != "" {
+		number += c.Decimal + frac
+	}
+
+	switch {
+	case c.Symbol == "":
+		return sign + number
+	case c.SymbolAfter:
+		return sign + number + " " + c.Symbol
+	case len(c.Symbol) > 1 && c.Symbol == c.Code:
+		return sign + c.Symbol + " " + number
+	default:
+		return sign + c.Symbol + number
+	}
+}
@@ -0,0 +1,45 @@
+package growatt
+
+import "testing"
+
+func TestPlantFormatMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		plant    Plant
+		amount   float64
+		expected string
+	}{
+		{"USD code", Plant{MoneyUnit: "USD"}, 1234.5, "$1,234.50"},
+		{"dollar symbol", Plant{MoneyUnit: "$", MoneyUnitText: "$"}, 1234567.891, "$1,234,567.89"},
+		{"EUR symbol after", Plant{MoneyUnit: "€", MoneyUnitText: "EUR"}, 1234.56, "1.234,56 €"},
+		{"GBP", Plant{MoneyUnitText: "gbp"}, 999.999, "£1,000.00"},
+		{"JPY no decimals", Plant{MoneyUnit: "JPY"}, 123456.7, "¥123,457"},
+		{"CHF code prefix", Plant{MoneyUnit: "CHF"}, 1234.5, "CHF 1'234.50"},
+		{"SEK", Plant{MoneyUnit: "SEK"}, 12345, "12 345,00 kr"},
+		{"negative", Plant{MoneyUnit: "USD"}, -42.1, "-$42.10"},
+		{"negative rounds to zero", Plant{MoneyUnit: "USD"}, -0.001, "$0.00"},
+		{"small amount", Plant{MoneyUnit: "EUR"}, 0.5, "0,50 €"},
+		{"unknown unit", Plant{MoneyUnit: "ZAR"}, 1234.5, "1,234.50 ZAR"},
+		{"no unit", Plant{}, 1234.5, "1,234.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plant.FormatMoney(tt.amount); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlantCurrency(t *testing.T) {
+	c := Plant{MoneyUnit: "€"}.Currency()
+	if c.Code != "EUR" || !c.SymbolAfter {
+		t.Errorf("expected EUR with symbol after, got %+v", c)
+	}
+
+	c = Plant{MoneyUnit: "ZAR"}.Currency()
+	if c.Code != "" || c.Symbol != "ZAR" {
+		t.Errorf("expected unknown currency with ZAR symbol, got %+v", c)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,12 +22,80 @@ func (c *Client) ListPlants(ctx context.Context) ([]Plant, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkCount("plant/list", data.Count, len(data.Plants)); err != nil {
+		return nil, err
+	}
+
+	plants, dropped := dedupePlants(data.Plants)
+	if len(dropped) > 0 {
+		c.warn("plant/list returned duplicate plant IDs %s; kept the first of each", strings.Join(dropped, ", "))
+	}
+	return plants, nil
+}
+
+// dedupePlants drops repeated plant IDs, keeping the first occurrence, and
+// returns the IDs of the dropped entries. Some accounts list the same plant
+// twice, which would otherwise defeat single-plant auto-detection.
+func dedupePlants(plants []Plant) ([]Plant, []string) {
+	seen := make(map[string]bool, len(plants))
+	result := plants[:0]
+	var dropped []string
+	for _, p := range plants {
+		id := p.PlantID.String()
+		if seen[id] {
+			dropped = append(dropped, id)
+			continue
+		}
+		seen[id] = true
+		result = append(result, p)
+	}
+	return result, dropped
+}
+
+// PlantSummary is the current output of a single plant
+type PlantSummary struct {
+	PlantID      string  `json:"plant_id"`
+	PlantName    string  `json:"plant_name"`
+	CurrentPower float64 `json:"current_power"` // W
+	TodayEnergy  float64 `json:"today_energy"`  // kWh
+	TotalEnergy  float64 `json:"total_energy"`  // kWh
+}
 
-	return data.Plants, nil
+// AccountSummary is the combined output of all plants on the account
+type AccountSummary struct {
+	CurrentPower float64        `json:"current_power"` // W
+	TodayEnergy  float64        `json:"today_energy"`  // kWh
+	TotalEnergy  float64        `json:"total_energy"`  // kWh
+	Plants       []PlantSummary `json:"plants"`
+}
+
+// GetAccountSummary sums current power and energy across all plants on the account
+func (c *Client) GetAccountSummary(ctx context.Context) (*AccountSummary, error) {
+	plants, err := c.ListPlants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AccountSummary{Plants: make([]PlantSummary, 0, len(plants))}
+	for _, p := range plants {
+		ps := PlantSummary{
+			PlantID:      p.PlantID.String(),
+			PlantName:    p.PlantName,
+			CurrentPower: p.CurrentPower.Float64(),
+			TodayEnergy:  p.TodayEnergy.Float64(),
+			TotalEnergy:  p.TotalEnergy.Float64(),
+		}
+		summary.CurrentPower += ps.CurrentPower
+		summary.TodayEnergy += ps.TodayEnergy
+		summary.TotalEnergy += ps.TotalEnergy
+		summary.Plants = append(summary.Plants, ps)
+	}
+
+	return summary, nil
 }
 
 // GetPlantDetails returns details for a specific plant
-func (c *Client) GetPlantDetails(ctx context.Context, plantID string) (*Plant, error) {
+func (c *Client) GetPlantDetails(ctx context.Context, plantID string) (*PlantDetails, error) {
 	params := url.Values{}
 	params.Set("plant_id", plantID)
 
@@ -35,7 +104,7 @@ func (c *Client) GetPlantDetails(ctx context.Context, plantID string) (*Plant, e
 		return nil, err
 	}
 
-	return parseResponse[Plant](body)
+	return parseResponse[PlantDetails](body)
 }
 
 // GetPlantData returns energy overview for a plant
@@ -51,36 +120,109 @@ func (c *Client) GetPlantData(ctx context.Context, plantID string) (*PlantData,
 	return parseResponse[PlantData](body)
 }
 
-// GetPlantPower returns 5-minute interval power data for a specific date
-func (c *Client) GetPlantPower(ctx context.Context, plantID string, date time.Time) (*PowerData, error) {
+// GetPlantStorage returns the plant-level battery overview. Plants without
+// storage return ErrNoStorage.
+func (c *Client) GetPlantStorage(ctx context.Context, plantID string) (*PlantStorage, error) {
+	params := url.Values{}
+	params.Set("plant_id", plantID)
+
+	body, err := c.get(ctx, "plant/storage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := parseResponse[PlantStorage](body)
+	if err != nil {
+		return nil, err
+	}
+	if storage.Capacity.Float64() <= 0 {
+		return nil, fmt.Errorf("plant %s: %w", plantID, ErrNoStorage)
+	}
+
+	return storage, nil
+}
+
+// PowerOption narrows a plant power query
+type PowerOption func(url.Values)
+
+// WithStartTime asks plant/power for readings from the time of day of t
+// (sent as start_time=HH:MM). Server versions without intraday windows
+// ignore it and return the whole day.
+func WithStartTime(t time.Time) PowerOption {
+	return func(params url.Values) {
+		params.Set("start_time", t.Format("15:04"))
+	}
+}
+
+// WithEndTime asks plant/power for readings up to the time of day of t
+// (sent as end_time=HH:MM). Server versions without intraday windows ignore
+// it and return the whole day.
+func WithEndTime(t time.Time) PowerOption {
+	return func(params url.Values) {
+		params.Set("end_time", t.Format("15:04"))
+	}
+}
+
+// GetPlantPowerRaw returns the unconverted power response for a specific date,
+// with time keys exactly as returned by the API
+func (c *Client) GetPlantPowerRaw(ctx context.Context, plantID string, date time.Time, opts ...PowerOption) (*PowerDataRaw, error) {
 	params := url.Values{}
 	params.Set("plant_id", plantID)
 	params.Set("date", date.Format("2006-01-02"))
+	for _, opt := range opts {
+		opt(params)
+	}
 
 	body, err := c.get(ctx, "plant/power", params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the raw response format
 	raw, err := parseResponse[PowerDataRaw](body)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkCount("plant/power", raw.Count, len(raw.Powers)); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// GetPlantPower returns 5-minute interval power data for a specific date.
+// Options such as WithStartTime narrow it to an intraday window.
+func (c *Client) GetPlantPower(ctx context.Context, plantID string, date time.Time, opts ...PowerOption) (*PowerData, error) {
+	raw, err := c.GetPlantPowerRaw(ctx, plantID, date, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order by raw timestamp so that, when several readings normalize to the
+	// same HH:MM, the later raw timestamp wins under MergeReplace
+	keys := make([]string, 0, len(raw.Powers))
+	for timeStr := range raw.Powers {
+		keys = append(keys, timeStr)
+	}
+	sort.Strings(keys)
 
-	// Convert to sorted slice
-	powers := make([]PowerDataPoint, 0, len(raw.Powers))
-	for timeStr, power := range map[string]float64(raw.Powers) {
-		powers = append(powers, PowerDataPoint{
+	points := make([]PowerDataPoint, 0, len(keys))
+	for _, timeStr := range keys {
+		points = append(points, PowerDataPoint{
 			Time:  timeStr,
-			Power: power,
+			Power: raw.Powers[timeStr],
 		})
 	}
 
-	// Sort by time
-	sort.Slice(powers, func(i, j int) bool {
-		return powers[i].Time < powers[j].Time
-	})
+	// Normalize to HH:MM, de-duplicate and sort by time
+	powers, err := MergePowerSeries(c.duplicateMode, points)
+	if err != nil {
+		return nil, err
+	}
+	if c.sortDescending {
+		sort.SliceStable(powers, func(i, j int) bool {
+			return powers[i].Time > powers[j].Time
+		})
+	}
 
 	return &PowerData{
 		PlantID: FlexString(raw.PlantID),
@@ -89,7 +231,10 @@ func (c *Client) GetPlantPower(ctx context.Context, plantID string, date time.Ti
 	}, nil
 }
 
-// GetPlantPowerRange fetches power data for a date range
+// GetPlantPowerRange fetches power data for a date range. Days without
+// readings are included as empty entries unless WithSkipEmptyDays is set.
+// With WithSortDescending both the days and the points within each day are
+// returned newest first.
 func (c *Client) GetPlantPowerRange(ctx context.Context, plantID string, from, to time.Time) ([]PowerData, error) {
 	var results []PowerData
 
@@ -106,15 +251,24 @@ func (c *Client) GetPlantPowerRange(ctx context.Context, plantID string, from, t
 			return results, fmt.Errorf("fetching power for %s: %w", current.Format("2006-01-02"), err)
 		}
 
-		results = append(results, *data)
+		if len(data.Powers) > 0 || !c.skipEmptyDays {
+			results = append(results, *data)
+		}
 		current = current.AddDate(0, 0, 1)
 	}
 
+	if c.sortDescending {
+		// Days are fetched oldest first; newest first applies across days too
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
 	return results, nil
 }
 
-// GetPlantEnergy returns historical energy data
-func (c *Client) GetPlantEnergy(ctx context.Context, plantID, startDate, endDate string, timeUnit TimeUnit) (*EnergyData, error) {
+// GetPlantEnergyRaw returns historical energy data as the API reports it,
+// keyed by period, without converting it to a sorted slice
+func (c *Client) GetPlantEnergyRaw(ctx context.Context, plantID, startDate, endDate string, timeUnit TimeUnit) (*EnergyDataRaw, error) {
 	params := url.Values{}
 	params.Set("plant_id", plantID)
 	params.Set("start_date", startDate)
@@ -126,11 +280,23 @@ func (c *Client) GetPlantEnergy(ctx context.Context, plantID, startDate, endDate
 		return nil, err
 	}
 
-	// Parse the raw response format
 	raw, err := parseResponse[EnergyDataRaw](body)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkCount("plant/energy", raw.Count, len(raw.Datas)); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// GetPlantEnergy returns historical energy data
+func (c *Client) GetPlantEnergy(ctx context.Context, plantID, startDate, endDate string, timeUnit TimeUnit) (*EnergyData, error) {
+	raw, err := c.GetPlantEnergyRaw(ctx, plantID, startDate, endDate, timeUnit)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert map to sorted slice
 	datas := make([]EnergyDataPoint, 0, len(raw.Datas))
@@ -143,6 +309,9 @@ func (c *Client) GetPlantEnergy(ctx context.Context, plantID, startDate, endDate
 
 	// Sort by date
 	sort.Slice(datas, func(i, j int) bool {
+		if c.sortDescending {
+			return datas[i].Date > datas[j].Date
+		}
 		return datas[i].Date < datas[j].Date
 	})
 
@@ -152,37 +321,181 @@ func (c *Client) GetPlantEnergy(ctx context.Context, plantID, startDate, endDate
 	}, nil
 }
 
-// ParsePowerData converts raw power data to parsed format with hour/minute
-func ParsePowerData(data *PowerData) ([]ParsedPowerData, error) {
-	date, err := time.Parse("2006-01-02", data.Date)
-	if err != nil {
-		return nil, fmt.Errorf("parsing date %s: %w", data.Date, err)
+// MaxEnergyDayRange is the longest span the API accepts for day-unit energy queries
+const MaxEnergyDayRange = 7
+
+// GetPlantEnergyRange fetches daily energy for a date range, splitting it into
+// chunks the API accepts and merging the results in the client's sort order
+func (c *Client) GetPlantEnergyRange(ctx context.Context, plantID string, from, to time.Time) (*EnergyData, error) {
+	result := &EnergyData{PlantID: FlexString(plantID)}
+
+	start := from
+	for !start.After(to) {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		end := start.AddDate(0, 0, MaxEnergyDayRange-1)
+		if end.After(to) {
+			end = to
+		}
+
+		data, err := c.GetPlantEnergy(ctx, plantID, start.Format("2006-01-02"), end.Format("2006-01-02"), TimeUnitDay)
+		if err != nil {
+			return result, fmt.Errorf("fetching energy for %s to %s: %w", start.Format("2006-01-02"), end.Format("2006-01-02"), err)
+		}
+
+		if c.sortDescending {
+			result.Datas = append(data.Datas, result.Datas...)
+		} else {
+			result.Datas = append(result.Datas, data.Datas...)
+		}
+		start = end.AddDate(0, 0, 1)
 	}
 
-	result := make([]ParsedPowerData, 0, len(data.Powers))
-	for _, p := range data.Powers {
-		timeStr := p.Time
+	return result, nil
+}
+
+// MaxFaultsPerPage is the largest page size the API accepts for fault history
+const MaxFaultsPerPage = 100
+
+// maxFaultPages bounds paging when the API omits the count or ignores the
+// page parameter
+const maxFaultPages = 50
+
+// GetPlantFaults returns the faults raised on one day, fetching every page.
+// Paging stops at a short page, once the reported count is reached, or when
+// a page repeats the previous one.
+func (c *Client) GetPlantFaults(ctx context.Context, plantID string, date time.Time) ([]Fault, error) {
+	var faults, previous []Fault
+	count := 0
 
-		// Handle full datetime format "YYYY-MM-DD HH:MM"
-		if strings.Contains(timeStr, " ") {
-			parts := strings.Split(timeStr, " ")
-			if len(parts) == 2 {
-				timeStr = parts[1] // Extract just the time part
-			}
+	for page := 1; ; page++ {
+		if page > maxFaultPages {
+			return faults, fmt.Errorf("plant/alarm: stopped after %d pages", maxFaultPages)
 		}
 
-		parts := strings.Split(timeStr, ":")
-		if len(parts) < 2 {
-			continue
+		params := url.Values{}
+		params.Set("plant_id", plantID)
+		params.Set("date", date.Format("2006-01-02"))
+		params.Set("page", strconv.Itoa(page))
+		params.Set("perpage", strconv.Itoa(MaxFaultsPerPage))
+
+		body, err := c.get(ctx, "plant/alarm", params)
+		if err != nil {
+			return faults, err
 		}
 
-		hour, err := strconv.Atoi(parts[0])
+		data, err := parseResponse[PlantFaultData](body)
 		if err != nil {
-			continue
+			return faults, err
+		}
+
+		// A server that ignores page keeps returning the same page
+		if page > 1 && slices.Equal(data.Alarms, previous) {
+			break
+		}
+		previous = data.Alarms
+
+		faults = append(faults, data.Alarms...)
+		count = data.Count
+		if len(data.Alarms) < MaxFaultsPerPage || (count > 0 && len(faults) >= count) {
+			break
+		}
+	}
+
+	if err := c.checkCount("plant/alarm", count, len(faults)); err != nil {
+		return nil, err
+	}
+	return faults, nil
+}
+
+// GetPlantFaultsRange returns all faults raised between from and to inclusive,
+// fetched one day at a time and sorted by start time
+func (c *Client) GetPlantFaultsRange(ctx context.Context, plantID string, from, to time.Time) ([]Fault, error) {
+	var results []Fault
+
+	current := from
+	for !current.After(to) {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
 		}
 
-		minute, err := strconv.Atoi(parts[1])
+		faults, err := c.GetPlantFaults(ctx, plantID, current)
 		if err != nil {
+			return results, fmt.Errorf("fetching faults for %s: %w", current.Format("2006-01-02"), err)
+		}
+
+		results = append(results, faults...)
+		current = current.AddDate(0, 0, 1)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].StartTime < results[j].StartTime
+	})
+
+	return results, nil
+}
+
+// EnergyReconciliation compares summed energy data against a reported total
+type EnergyReconciliation struct {
+	Summed      float64 // Sum of all energy data points (kWh)
+	Reported    float64 // Total reported by the API (kWh)
+	Difference  float64 // Summed minus reported (kWh)
+	PercentDiff float64 // Difference as a percentage of reported
+}
+
+// ReconcilePlantEnergy sums the energy data points and compares them to a
+// reported total such as Plant.TotalEnergy, to help catch missing days
+func ReconcilePlantEnergy(energy *EnergyData, reportedTotal float64) EnergyReconciliation {
+	var summed float64
+	if energy != nil {
+		for _, d := range energy.Datas {
+			summed += d.Energy
+		}
+	}
+
+	result := EnergyReconciliation{
+		Summed:     summed,
+		Reported:   reportedTotal,
+		Difference: summed - reportedTotal,
+	}
+
+	if reportedTotal != 0 {
+		result.PercentDiff = result.Difference / reportedTotal * 100
+	} else if summed != 0 {
+		result.PercentDiff = 100
+	}
+
+	return result
+}
+
+// ParsePowerData converts raw power data to parsed format with hour/minute.
+// A "24:00" reading is reported as hour 23, minute 60. Readings with
+// unparseable or out-of-range times such as "25:61" are skipped.
+func ParsePowerData(data *PowerData) ([]ParsedPowerData, error) {
+	result, _, err := ParsePowerDataSkipped(data)
+	return result, err
+}
+
+// ParsePowerDataSkipped is ParsePowerData that also reports how many readings
+// were skipped for unparseable or out-of-range times
+func ParsePowerDataSkipped(data *PowerData) ([]ParsedPowerData, int, error) {
+	date, err := time.Parse("2006-01-02", data.Date)
+	if err != nil {
+		return nil, 0, fmt.Errorf("parsing date %s: %w", data.Date, err)
+	}
+
+	skipped := 0
+	result := make([]ParsedPowerData, 0, len(data.Powers))
+	for _, p := range data.Powers {
+		timeStr, hour, minute, ok := parseClock(p.Time)
+		if !ok {
+			skipped++
 			continue
 		}
 
@@ -195,5 +508,99 @@ func ParsePowerData(data *PowerData) ([]ParsedPowerData, error) {
 		})
 	}
 
-	return result, nil
+	return result, skipped, nil
+}
+
+// parseClock extracts the time of day from "HH:MM" or "YYYY-MM-DD HH:MM",
+// returning the time part with its hour and minute. ok is false for
+// unparseable times and for hours or minutes out of range.
+func parseClock(value string) (timeStr string, hour, minute int, ok bool) {
+	timeStr = value
+
+	// Handle full datetime format "YYYY-MM-DD HH:MM"
+	if strings.Contains(timeStr, " ") {
+		parts := strings.Split(timeStr, " ")
+		if len(parts) == 2 {
+			timeStr = parts[1] // Extract just the time part
+		}
+	}
+
+	parts := strings.Split(timeStr, ":")
+	if len(parts) < 2 {
+		return timeStr, 0, 0, false
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return timeStr, 0, 0, false
+	}
+
+	minute, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return timeStr, 0, 0, false
+	}
+
+	// An end-of-day "24:00" reading belongs to the last hour of the same
+	// day; minute 60 keeps it ordered after 23:55 and preserves the interval
+	if hour == 24 && minute == 0 {
+		hour, minute = 23, 60
+	} else if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return timeStr, 0, 0, false
+	}
+
+	return timeStr, hour, minute, true
+}
+
+// DefaultIntervalMinutes is the assumed sampling interval when none can be detected
+const DefaultIntervalMinutes = 5
+
+// DetectIntervalMinutes returns the smallest gap between readings given as
+// minutes of the day, falling back to DefaultIntervalMinutes when it cannot be
+// determined
+func DetectIntervalMinutes(minutes []int) int {
+	sorted := make([]int, len(minutes))
+	copy(sorted, minutes)
+	sort.Ints(sorted)
+
+	interval := 0
+	for i := 1; i < len(sorted); i++ {
+		gap := sorted[i] - sorted[i-1]
+		if gap > 0 && (interval == 0 || gap < interval) {
+			interval = gap
+		}
+	}
+
+	if interval == 0 {
+		return DefaultIntervalMinutes
+	}
+	return interval
+}
+
+// DailyEnergyKWh integrates a day's power readings into energy (kWh) in a
+// single pass, without parsing and aggregating them first. An interval of 0
+// detects the sampling interval from the readings.
+func DailyEnergyKWh(data *PowerData, interval time.Duration) (float64, error) {
+	if _, err := time.Parse("2006-01-02", data.Date); err != nil {
+		return 0, fmt.Errorf("parsing date %s: %w", data.Date, err)
+	}
+
+	var sum float64
+	var minutes []int
+	for _, p := range data.Powers {
+		_, hour, minute, ok := parseClock(p.Time)
+		if !ok {
+			continue
+		}
+		sum += p.Power
+		if interval == 0 {
+			minutes = append(minutes, hour*60+minute)
+		}
+	}
+
+	if interval == 0 {
+		interval = time.Duration(DetectIntervalMinutes(minutes)) * time.Minute
+	}
+
+	// W * h / 1000 = kWh
+	return sum * interval.Hours() / 1000, nil
 }
@@ -2,10 +2,18 @@ package growatt
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -68,6 +76,144 @@ func TestListPlants(t *testing.T) {
 	}
 }
 
+func TestListPlants_DuplicateIDs(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_list_duplicate.json"))
+	})
+	defer server.Close()
+
+	var warnings []string
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithWarnings(func(msg string) { warnings = append(warnings, msg) }),
+	)
+
+	plants, err := client.ListPlants(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plants) != 1 {
+		t.Fatalf("expected 1 plant after de-duplication, got %d", len(plants))
+	}
+
+	if plants[0].PlantName != "Home Solar" {
+		t.Errorf("expected first occurrence to be kept, got %q", plants[0].PlantName)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "duplicate plant IDs 12345") {
+		t.Errorf("expected a warning naming the dropped plant ID, got %q", warnings)
+	}
+}
+
+func TestGetPlantDetails(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plant/details" {
+			t.Errorf("expected path /plant/details, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("plant_id") != "12345" {
+			t.Errorf("expected plant_id 12345, got %s", r.URL.Query().Get("plant_id"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_details.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	details, err := client.GetPlantDetails(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if details.PlantName != "Home Solar" || details.TotalEnergy.Float64() != 15234.8 {
+		t.Errorf("unexpected plant fields: %+v", details.Plant)
+	}
+	if details.DeviceSummary.DeviceCount != 3 {
+		t.Errorf("expected 3 devices, got %d", details.DeviceSummary.DeviceCount)
+	}
+	if details.DeviceSummary.InstalledCapacity.Float64() != 9.6 {
+		t.Errorf("expected installed capacity 9.6, got %f", details.DeviceSummary.InstalledCapacity.Float64())
+	}
+}
+
+func TestGetPlantStorage(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plant/storage" {
+			t.Errorf("expected path /plant/storage, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_storage.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	storage, err := client.GetPlantStorage(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if storage.Soc.Float64() != 87 {
+		t.Errorf("expected SOC 87, got %f", storage.Soc.Float64())
+	}
+	if storage.ChargePower.Float64() != 1250.5 || storage.DischargePower.Float64() != 0 {
+		t.Errorf("unexpected charge/discharge: %f/%f", storage.ChargePower.Float64(), storage.DischargePower.Float64())
+	}
+	if storage.Capacity.Float64() != 10.2 {
+		t.Errorf("expected capacity 10.2, got %f", storage.Capacity.Float64())
+	}
+}
+
+func TestGetPlantStorage_NoStorage(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": ""}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	_, err := client.GetPlantStorage(context.Background(), "12346")
+	if !errors.Is(err, ErrNoStorage) {
+		t.Errorf("expected ErrNoStorage, got %v", err)
+	}
+}
+
+func TestGetAccountSummary(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_list.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	summary, err := client.GetAccountSummary(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.CurrentPower != 6623.5 {
+		t.Errorf("expected current power 6623.5, got %f", summary.CurrentPower)
+	}
+	if math.Abs(summary.TodayEnergy-50.7) > 1e-9 {
+		t.Errorf("expected today energy 50.7, got %f", summary.TodayEnergy)
+	}
+	if math.Abs(summary.TotalEnergy-23734.8) > 1e-9 {
+		t.Errorf("expected total energy 23734.8, got %f", summary.TotalEnergy)
+	}
+
+	if len(summary.Plants) != 2 {
+		t.Fatalf("expected 2 plants, got %d", len(summary.Plants))
+	}
+	if summary.Plants[1].PlantID != "12346" || summary.Plants[1].CurrentPower != 2100 {
+		t.Errorf("unexpected second plant: %+v", summary.Plants[1])
+	}
+}
+
 func TestListPlantsError(t *testing.T) {
 	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -138,6 +284,135 @@ func TestGetPlantPower(t *testing.T) {
 	}
 }
 
+func TestGetPlantPowerRaw(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"error_code": 0,
+			"error_msg": "success",
+			"data": {
+				"plant_id": "12345",
+				"count": 3,
+				"powers": {
+					"2025-11-02 01:00": 0,
+					"2025-11-02 01:00:00": 10,
+					"12:00": 4500.5
+				}
+			}
+		}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	testDate, _ := time.Parse("2006-01-02", "2025-11-02")
+	raw, err := client.GetPlantPowerRaw(ctx, "12345", testDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if raw.Count != 3 {
+		t.Errorf("expected count 3, got %d", raw.Count)
+	}
+
+	// Original keys are preserved, including full datetimes that normalize to the same time
+	for key, want := range map[string]float64{
+		"2025-11-02 01:00":    0,
+		"2025-11-02 01:00:00": 10,
+		"12:00":               4500.5,
+	} {
+		got, ok := raw.Powers[key]
+		if !ok {
+			t.Errorf("expected raw key %q to be preserved, got %v", key, raw.Powers)
+			continue
+		}
+		if got != want {
+			t.Errorf("key %q: expected %f, got %f", key, want, got)
+		}
+	}
+
+	// The friendly wrapper normalizes times
+	power, err := client.GetPlantPower(ctx, "12345", testDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range power.Powers {
+		if len(p.Time) != 5 {
+			t.Errorf("expected normalized HH:MM time, got %q", p.Time)
+		}
+	}
+}
+
+func TestGetPlantPowerSingleDigitHours(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {
+			"plant_id": "12345",
+			"powers": {"10:00": 300, "9:00": 100, "9:55": 200, "2025-02-03 8:30": 50}
+		}}`))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	power, err := client.GetPlantPower(context.Background(), "12345", time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"08:30", "09:00", "09:55", "10:00"}
+	if len(power.Powers) != len(expected) {
+		t.Fatalf("expected %d points, got %d: %v", len(expected), len(power.Powers), power.Powers)
+	}
+	for i, p := range power.Powers {
+		if p.Time != expected[i] {
+			t.Errorf("point %d: expected %s, got %s", i, expected[i], p.Time)
+		}
+	}
+}
+
+func TestGetPlantPowerDuplicateTimes(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_power_dst.json"))
+	})
+	defer server.Close()
+
+	testDate, _ := time.Parse("2006-01-02", "2025-11-02")
+
+	tests := []struct {
+		name     string
+		opts     []ClientOption
+		expected float64
+	}{
+		{name: "default keeps later raw timestamp", expected: 25},
+		{name: "replace", opts: []ClientOption{WithDuplicateMode(MergeReplace)}, expected: 25},
+		{name: "sum", opts: []ClientOption{WithDuplicateMode(MergeSum)}, expected: 35},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ClientOption{WithBaseURL(server.URL + "/"), WithRateLimit(0)}, tt.opts...)
+			client := NewClient("test-token", opts...)
+
+			power, err := client.GetPlantPower(context.Background(), "12345", testDate)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(power.Powers) != 3 {
+				t.Fatalf("expected 3 de-duplicated points, got %d: %v", len(power.Powers), power.Powers)
+			}
+
+			p := power.Powers[1]
+			if p.Time != "01:30" || p.Power != tt.expected {
+				t.Errorf("expected 01:30 = %.0f, got %s = %.0f", tt.expected, p.Time, p.Power)
+			}
+		})
+	}
+}
+
 func TestGetPlantEnergy(t *testing.T) {
 	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/plant/energy" {
@@ -187,6 +462,182 @@ func TestGetPlantEnergy(t *testing.T) {
 	}
 }
 
+func TestGetPlantPower_TimeWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []PowerOption
+		wantStart string
+		wantEnd   string
+	}{
+		{"full day", nil, "", ""},
+		{"window", []PowerOption{
+			WithStartTime(time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)),
+			WithEndTime(time.Date(2025, 2, 3, 12, 30, 0, 0, time.UTC)),
+		}, "12:00", "12:30"},
+		{"start only", []PowerOption{WithStartTime(time.Date(2025, 2, 3, 6, 5, 0, 0, time.UTC))}, "06:05", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Write(loadTestData(t, "plant_power.json"))
+			})
+			defer server.Close()
+
+			client := newTestClient(t, server)
+			if _, err := client.GetPlantPower(context.Background(), "12345", time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), tt.opts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if query.Get("date") != "2025-02-03" {
+				t.Errorf("expected date 2025-02-03, got %q", query.Get("date"))
+			}
+			for key, want := range map[string]string{"start_time": tt.wantStart, "end_time": tt.wantEnd} {
+				if _, sent := query[key]; sent != (want != "") || query.Get(key) != want {
+					t.Errorf("expected %s %q, got %q (sent: %v)", key, want, query.Get(key), sent)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPlantEnergyRaw(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plant/energy" {
+			t.Errorf("expected path /plant/energy, got %s", r.URL.Path)
+		}
+		w.Write(loadTestData(t, "plant_energy.json"))
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+
+	raw, err := client.GetPlantEnergyRaw(context.Background(), "12345", "2025-01-01", "2025-01-07", TimeUnitDay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var fixture struct {
+		Data struct {
+			Datas map[string]float64 `json:"datas"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(loadTestData(t, "plant_energy.json"), &fixture); err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	if raw.PlantID.String() != "12345" || raw.Count != 7 {
+		t.Errorf("unexpected plant ID %q or count %d", raw.PlantID.String(), raw.Count)
+	}
+	if !reflect.DeepEqual(raw.Datas, fixture.Data.Datas) {
+		t.Errorf("expected %v, got %v", fixture.Data.Datas, raw.Datas)
+	}
+}
+
+func TestGetPlantEnergyRange(t *testing.T) {
+	var chunks []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("start_date")
+		end := r.URL.Query().Get("end_date")
+		if r.URL.Query().Get("time_unit") != "day" {
+			t.Errorf("expected time_unit day, got %q", r.URL.Query().Get("time_unit"))
+		}
+		chunks = append(chunks, start+"/"+end)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"error_code": 0, "error_msg": "success", "data": {"plant_id": "12345", "count": 2, "datas": {%q: 10, %q: 20}}}`, start, end)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	from, _ := time.Parse("2006-01-02", "2025-01-01")
+	to, _ := time.Parse("2006-01-02", "2025-01-10")
+
+	energy, err := client.GetPlantEnergyRange(ctx, "12345", from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedChunks := []string{"2025-01-01/2025-01-07", "2025-01-08/2025-01-10"}
+	if len(chunks) != len(expectedChunks) {
+		t.Fatalf("expected %d requests, got %d: %v", len(expectedChunks), len(chunks), chunks)
+	}
+	for i := range expectedChunks {
+		if chunks[i] != expectedChunks[i] {
+			t.Errorf("chunk %d: expected %q, got %q", i, expectedChunks[i], chunks[i])
+		}
+	}
+
+	if len(energy.Datas) != 4 {
+		t.Fatalf("expected 4 data points, got %d", len(energy.Datas))
+	}
+	if energy.Datas[0].Date != "2025-01-01" || energy.Datas[3].Date != "2025-01-10" {
+		t.Errorf("expected data in date order, got %v", energy.Datas)
+	}
+}
+
+func TestSortDescending(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/plant/power":
+			w.Write(loadTestData(t, "plant_power.json"))
+		default:
+			q := r.URL.Query()
+			fmt.Fprintf(w, `{"error_code": 0, "data": {"datas": {%q: 10, %q: 20}}}`, q.Get("end_date"), q.Get("start_date"))
+		}
+	})
+	defer server.Close()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		firstPower  string
+		firstEnergy string
+		lastEnergy  string
+	}{
+		{name: "ascending by default", firstPower: "00:00", firstEnergy: "2025-01-01", lastEnergy: "2025-01-10"},
+		{name: "descending", opts: []ClientOption{WithSortDescending()}, firstPower: "23:55", firstEnergy: "2025-01-10", lastEnergy: "2025-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ClientOption{WithBaseURL(server.URL + "/"), WithRateLimit(0)}, tt.opts...)
+			client := NewClient("test-token", opts...)
+			ctx := context.Background()
+
+			power, err := client.GetPlantPower(ctx, "12345", from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if power.Powers[0].Time != tt.firstPower {
+				t.Errorf("expected first power at %s, got %s", tt.firstPower, power.Powers[0].Time)
+			}
+
+			energy, err := client.GetPlantEnergyRange(ctx, "12345", from, to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			datas := energy.Datas
+			if datas[0].Date != tt.firstEnergy || datas[len(datas)-1].Date != tt.lastEnergy {
+				t.Errorf("expected energy from %s to %s, got %v", tt.firstEnergy, tt.lastEnergy, datas)
+			}
+			for i := 1; i < len(datas); i++ {
+				if (datas[i].Date > datas[i-1].Date) == (tt.firstEnergy > tt.lastEnergy) {
+					t.Errorf("energy out of order at %d: %v", i, datas)
+				}
+			}
+		})
+	}
+}
+
 func TestGetPlantPowerRange(t *testing.T) {
 	callCount := 0
 	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
@@ -217,6 +668,37 @@ func TestGetPlantPowerRange(t *testing.T) {
 	}
 }
 
+func TestGetPlantPowerRange_SortDescending(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_power.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0), WithSortDescending())
+
+	from, _ := time.Parse("2006-01-02", "2025-02-01")
+	to, _ := time.Parse("2006-01-02", "2025-02-03")
+
+	data, err := client.GetPlantPowerRange(context.Background(), "12345", from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dates []string
+	for _, day := range data {
+		dates = append(dates, day.Date)
+		for i := 1; i < len(day.Powers); i++ {
+			if day.Powers[i].Time > day.Powers[i-1].Time {
+				t.Fatalf("%s: points out of order at %d", day.Date, i)
+			}
+		}
+	}
+	if want := []string{"2025-02-03", "2025-02-02", "2025-02-01"}; !reflect.DeepEqual(dates, want) {
+		t.Errorf("expected days %v, got %v", want, dates)
+	}
+}
+
 func TestParsePowerData(t *testing.T) {
 	powerData := &PowerData{
 		PlantID: "12345",
@@ -254,3 +736,429 @@ func TestParsePowerData(t *testing.T) {
 		t.Errorf("expected date %v, got %v", expectedDate, parsed[0].Date)
 	}
 }
+
+func TestParsePowerData_EndOfDay(t *testing.T) {
+	powerData := &PowerData{
+		Date: "2025-02-03",
+		Powers: []PowerDataPoint{
+			{Time: "23:55", Power: 10},
+			{Time: "24:00", Power: 5},
+		},
+	}
+
+	parsed, err := ParsePowerData(powerData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 parsed points, got %d", len(parsed))
+	}
+
+	p := parsed[1]
+	if p.Hour != 23 || p.Minute != 60 {
+		t.Errorf("expected hour 23, minute 60, got hour %d, minute %d", p.Hour, p.Minute)
+	}
+	if p.Date.Format("2006-01-02") != "2025-02-03" {
+		t.Errorf("expected reading to stay on 2025-02-03, got %s", p.Date.Format("2006-01-02"))
+	}
+}
+
+func TestParsePowerData_OutOfRangeTimes(t *testing.T) {
+	powerData := &PowerData{
+		Date: "2025-02-03",
+		Powers: []PowerDataPoint{
+			{Time: "00:00", Power: 1},
+			{Time: "25:61", Power: 2},
+			{Time: "-1:00", Power: 3},
+			{Time: "12:60", Power: 4},
+			{Time: "24:05", Power: 5},
+			{Time: "23:59", Power: 6},
+			{Time: "bogus", Power: 7},
+		},
+	}
+
+	parsed, skipped, err := ParsePowerDataSkipped(powerData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 5 {
+		t.Errorf("expected 5 skipped readings, got %d", skipped)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 parsed points, got %d", len(parsed))
+	}
+	if parsed[0].Hour != 0 || parsed[0].Minute != 0 {
+		t.Errorf("expected 00:00, got %d:%d", parsed[0].Hour, parsed[0].Minute)
+	}
+	if parsed[1].Hour != 23 || parsed[1].Minute != 59 || parsed[1].Power != 6 {
+		t.Errorf("expected 23:59 with power 6, got %+v", parsed[1])
+	}
+
+	// ParsePowerData drops the same readings
+	plain, err := ParsePowerData(powerData)
+	if err != nil || len(plain) != 2 {
+		t.Errorf("expected 2 parsed points, got %d (err %v)", len(plain), err)
+	}
+}
+
+func TestReconcilePlantEnergy(t *testing.T) {
+	energy := &EnergyData{
+		PlantID: "12345",
+		Datas: []EnergyDataPoint{
+			{Date: "2025-02-01", Energy: 20},
+			{Date: "2025-02-02", Energy: 30},
+			{Date: "2025-02-03", Energy: 50},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		energy      *EnergyData
+		reported    float64
+		summed      float64
+		percentDiff float64
+	}{
+		{
+			name:        "matching totals",
+			energy:      energy,
+			reported:    100,
+			summed:      100,
+			percentDiff: 0,
+		},
+		{
+			name:        "missing days",
+			energy:      energy,
+			reported:    125,
+			summed:      100,
+			percentDiff: -20,
+		},
+		{
+			name:        "summed exceeds reported",
+			energy:      energy,
+			reported:    80,
+			summed:      100,
+			percentDiff: 25,
+		},
+		{
+			name:        "zero reported",
+			energy:      energy,
+			reported:    0,
+			summed:      100,
+			percentDiff: 100,
+		},
+		{
+			name:        "nil energy",
+			energy:      nil,
+			reported:    0,
+			summed:      0,
+			percentDiff: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ReconcilePlantEnergy(tt.energy, tt.reported)
+			if result.Summed != tt.summed {
+				t.Errorf("expected summed %.2f, got %.2f", tt.summed, result.Summed)
+			}
+			if result.Reported != tt.reported {
+				t.Errorf("expected reported %.2f, got %.2f", tt.reported, result.Reported)
+			}
+			if result.Difference != tt.summed-tt.reported {
+				t.Errorf("expected difference %.2f, got %.2f", tt.summed-tt.reported, result.Difference)
+			}
+			if math.Abs(result.PercentDiff-tt.percentDiff) > 0.001 {
+				t.Errorf("expected percent diff %.2f, got %.2f", tt.percentDiff, result.PercentDiff)
+			}
+		})
+	}
+}
+
+func TestGetPlantFaultsRange(t *testing.T) {
+	var days map[string]json.RawMessage
+	if err := json.Unmarshal(loadTestData(t, "plant_faults.json"), &days); err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	var requested []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plant/alarm" {
+			t.Errorf("expected path /plant/alarm, got %s", r.URL.Path)
+		}
+		date := r.URL.Query().Get("date")
+		requested = append(requested, date)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(days[date])
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	faults, err := client.GetPlantFaultsRange(context.Background(), "12345", from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requested) != 3 {
+		t.Errorf("expected one request per day, got %v", requested)
+	}
+
+	expected := []struct {
+		start string
+		code  string
+	}{
+		{"2025-02-01 07:05:00", "411"},
+		{"2025-02-01 14:20:00", "202"},
+		{"2025-02-03 09:12:00", "117"},
+	}
+	if len(faults) != len(expected) {
+		t.Fatalf("expected %d faults, got %d", len(expected), len(faults))
+	}
+	for i, want := range expected {
+		if faults[i].StartTime != want.start || faults[i].Code.String() != want.code {
+			t.Errorf("fault %d: expected %s code %s, got %s code %s",
+				i, want.start, want.code, faults[i].StartTime, faults[i].Code.String())
+		}
+	}
+	if faults[2].DeviceSN.String() != "DEF654321" || faults[2].Status != 1 {
+		t.Errorf("unexpected fault fields: %+v", faults[2])
+	}
+}
+
+func TestGetPlantFaults_Paging(t *testing.T) {
+	const total = MaxFaultsPerPage + 5
+
+	var pages []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		n := MaxFaultsPerPage
+		if page == 2 {
+			n = total - MaxFaultsPerPage
+		}
+		alarms := make([]Fault, n)
+		for i := range alarms {
+			alarms[i].StartTime = fmt.Sprintf("2025-02-01 %02d:%02d:00", page, i%60)
+		}
+
+		data, _ := json.Marshal(map[string]any{
+			"error_code": 0,
+			"error_msg":  "success",
+			"data":       PlantFaultData{Count: total, Alarms: alarms},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	faults, err := client.GetPlantFaults(context.Background(), "12345", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(faults) != total {
+		t.Errorf("expected %d faults, got %d", total, len(faults))
+	}
+	if len(pages) != 2 || pages[0] != "1" || pages[1] != "2" {
+		t.Errorf("expected pages 1 and 2, got %v", pages)
+	}
+}
+
+func TestGetPlantFaults_PagingWithoutCount(t *testing.T) {
+	// Without a count the client pages until a short page arrives
+	const total = 2*MaxFaultsPerPage + 3
+
+	var pages []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		n := MaxFaultsPerPage
+		if page == 3 {
+			n = total - 2*MaxFaultsPerPage
+		}
+		alarms := make([]Fault, n)
+		for i := range alarms {
+			alarms[i].StartTime = fmt.Sprintf("2025-02-01 %02d:%02d:00", page, i%60)
+		}
+		data, _ := json.Marshal(map[string]any{
+			"error_code": 0,
+			"error_msg":  "success",
+			"data":       map[string]any{"alarms": alarms},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	faults, err := client.GetPlantFaults(context.Background(), "12345", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(faults) != total {
+		t.Errorf("expected %d faults, got %d", total, len(faults))
+	}
+	if len(pages) != 3 {
+		t.Errorf("expected 3 pages, got %v", pages)
+	}
+}
+
+func TestGetPlantFaults_ServerIgnoresPage(t *testing.T) {
+	// Full pages without a count, and the same page whatever is asked for
+	alarms := make([]Fault, MaxFaultsPerPage)
+	for i := range alarms {
+		alarms[i].StartTime = fmt.Sprintf("2025-02-01 %02d:%02d:00", i/60, i%60)
+	}
+	body, _ := json.Marshal(map[string]any{
+		"error_code": 0,
+		"error_msg":  "success",
+		"data":       map[string]any{"alarms": alarms},
+	})
+
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	faults, err := client.GetPlantFaults(context.Background(), "12345", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(faults) != MaxFaultsPerPage {
+		t.Errorf("expected the repeated page to be kept once, got %d faults", len(faults))
+	}
+	if requests != 2 {
+		t.Errorf("expected paging to stop at the repeated page, got %d requests", requests)
+	}
+}
+
+func TestGetPlantFaults_PageCap(t *testing.T) {
+	// Full pages of new alarms that never end
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		alarms := make([]Fault, MaxFaultsPerPage)
+		for i := range alarms {
+			alarms[i].StartTime = fmt.Sprintf("page %d alarm %d", requests, i)
+		}
+		data, _ := json.Marshal(map[string]any{
+			"error_code": 0,
+			"error_msg":  "success",
+			"data":       map[string]any{"alarms": alarms},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	if _, err := client.GetPlantFaults(context.Background(), "12345", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected an error once the page cap is reached")
+	}
+	if requests != maxFaultPages {
+		t.Errorf("expected %d requests, got %d", maxFaultPages, requests)
+	}
+}
+
+func TestGetPlantPowerRange_SkipEmptyDays(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("date") == "2025-02-02" {
+			w.Write([]byte(`{"error_code": 0, "error_msg": "success", "data": {"plant_id": 12345, "powers": {}}}`))
+			return
+		}
+		w.Write(loadTestData(t, "plant_power.json"))
+	})
+	defer server.Close()
+
+	from, _ := time.Parse("2006-01-02", "2025-02-01")
+	to, _ := time.Parse("2006-01-02", "2025-02-03")
+
+	tests := []struct {
+		name     string
+		opts     []ClientOption
+		expected []string
+	}{
+		{"default keeps empty day", nil, []string{"2025-02-01", "2025-02-02", "2025-02-03"}},
+		{"skip empty days", []ClientOption{WithSkipEmptyDays()}, []string{"2025-02-01", "2025-02-03"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ClientOption{WithBaseURL(server.URL + "/"), WithRateLimit(0)}, tt.opts...)
+			client := NewClient("test-token", opts...)
+
+			data, err := client.GetPlantPowerRange(context.Background(), "12345", from, to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(data) != len(tt.expected) {
+				t.Fatalf("expected %d days, got %d", len(tt.expected), len(data))
+			}
+			for i, date := range tt.expected {
+				if data[i].Date != date {
+					t.Errorf("day %d: expected %s, got %s", i, date, data[i].Date)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPlantPower_NestedDatas(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_power_nested.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token", WithBaseURL(server.URL+"/"), WithRateLimit(0), WithStrictParsing())
+	testDate, _ := time.Parse("2006-01-02", "2025-02-03")
+
+	power, err := client.GetPlantPower(context.Background(), "12345", testDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if power.PlantID.String() != "12345" {
+		t.Errorf("expected plant ID %q, got %q", "12345", power.PlantID.String())
+	}
+
+	expected := []PowerDataPoint{
+		{Time: "06:00", Power: 0},
+		{Time: "06:05", Power: 25.5},
+		{Time: "12:00", Power: 4523.5},
+		{Time: "12:05", Power: 4488.1},
+		{Time: "18:55", Power: 12.0},
+	}
+	if len(power.Powers) != len(expected) {
+		t.Fatalf("expected %d readings, got %d", len(expected), len(power.Powers))
+	}
+	for i, want := range expected {
+		if power.Powers[i] != want {
+			t.Errorf("reading %d: expected %+v, got %+v", i, want, power.Powers[i])
+		}
+	}
+}
+
+func TestPowerDataRaw_PrefersTopLevelPowers(t *testing.T) {
+	var raw PowerDataRaw
+	data := `{"plant_id": "1", "powers": {"12:00": 100}, "datas": {"powers": {"13:00": 200}}}`
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(raw.Powers) != 1 || raw.Powers["12:00"] != 100 {
+		t.Errorf("expected top-level powers, got %v", raw.Powers)
+	}
+}
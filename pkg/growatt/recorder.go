@@ -0,0 +1,32 @@
+package growatt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// recordingPath returns the file an endpoint's responses are recorded to,
+// flattening the endpoint path so "device/tlx/tlx_data" becomes "device_tlx_tlx_data.json"
+func (c *Client) recordingPath(endpoint string) string {
+	name := strings.ReplaceAll(strings.Trim(endpoint, "/"), "/", "_")
+	return filepath.Join(c.recordDir, name+".json")
+}
+
+// recordResponse writes a raw response body to the recorder directory,
+// replacing any earlier recording of the same endpoint
+func (c *Client) recordResponse(endpoint string, body []byte) error {
+	if c.recordDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.recordDir, 0755); err != nil {
+		return fmt.Errorf("creating recorder directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.recordingPath(endpoint), body, 0644); err != nil {
+		return fmt.Errorf("recording response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package growatt
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithResponseRecorder(t *testing.T) {
+	fixture := loadTestData(t, "min_history.json")
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/plant/list" {
+			w.Write(loadTestData(t, "plant_list.json"))
+			return
+		}
+		w.Write(fixture)
+	})
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "recorded")
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithResponseRecorder(dir),
+	)
+
+	ctx := context.Background()
+	if _, err := client.ListPlants(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetMINInverterHistory(ctx, "ABC123456", time.Now(), "UTC"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		file     string
+		expected []byte
+	}{
+		{"plant_list.json", loadTestData(t, "plant_list.json")},
+		{"device_tlx_tlx_data.json", fixture},
+	}
+	for _, tt := range tests {
+		got, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Errorf("expected recording %s: %v", tt.file, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.expected) {
+			t.Errorf("%s: recorded body differs from response", tt.file)
+		}
+	}
+}
+
+func TestWithResponseRecorder_Disabled(t *testing.T) {
+	client := NewClient("test-token")
+	if err := client.recordResponse("plant/list", []byte("{}")); err != nil {
+		t.Errorf("expected no-op without a recorder directory, got %v", err)
+	}
+}
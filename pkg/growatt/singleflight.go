@@ -0,0 +1,76 @@
+package growatt
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errFlightPanicked is returned to callers sharing a request whose leader panicked
+var errFlightPanicked = errors.New("shared request panicked")
+
+// flightCall is an in-flight request shared by duplicate callers
+type flightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// flightGroup de-duplicates concurrent identical requests so they share one response
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn once per key among concurrent callers; later callers wait for and
+// receive the result of the call already in flight. The call runs under the
+// first caller's context, so a waiter whose own context is still live retries
+// when the shared call was cancelled rather than inheriting the cancellation.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	for {
+		g.mu.Lock()
+		if g.calls == nil {
+			g.calls = make(map[string]*flightCall)
+		}
+		call, ok := g.calls[key]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if isContextError(call.err) && ctx.Err() == nil {
+			continue
+		}
+		return call.body, call.err
+	}
+
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// Release waiters even if fn panics
+	completed := false
+	defer func() {
+		if !completed {
+			call.err = errFlightPanicked
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.body, call.err = fn()
+	completed = true
+	return call.body, call.err
+}
+
+// isContextError reports whether err comes from a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
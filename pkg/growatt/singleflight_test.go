@@ -0,0 +1,173 @@
+package growatt
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithSingleflight(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadTestData(t, "plant_list.json"))
+	})
+	defer server.Close()
+
+	client := NewClient("test-token",
+		WithBaseURL(server.URL+"/"),
+		WithRateLimit(0),
+		WithSingleflight(),
+	)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	results := make([][]Plant, 2)
+	errs := make([]error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = client.ListPlants(ctx)
+		}(i)
+	}
+
+	// Give both callers time to join the same flight before responding
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: unexpected error: %v", i, errs[i])
+		}
+		if len(results[i]) != 2 {
+			t.Errorf("caller %d: expected 2 plants, got %d", i, len(results[i]))
+		}
+	}
+
+	// Sequential calls are not de-duplicated
+	if _, err := client.ListPlants(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests after sequential call, got %d", n)
+	}
+}
+
+func TestFlightGroupDistinctKeys(t *testing.T) {
+	var g flightGroup
+	var calls int32
+
+	fn := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		return []byte("ok"), nil
+	}
+
+	if _, err := g.do(context.Background(), "plant/data?plant_id=1", fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := g.do(context.Background(), "plant/data?plant_id=2", fn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls for distinct keys, got %d", calls)
+	}
+}
+
+func TestFlightGroup_WaiterRetriesCancelledCall(t *testing.T) {
+	var g flightGroup
+	var calls int32
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := g.do(leaderCtx, "plant/list", func() ([]byte, error) {
+			atomic.AddInt32(&calls, 1)
+			close(started)
+			<-leaderCtx.Done()
+			return nil, leaderCtx.Err()
+		})
+		leaderDone <- err
+	}()
+	<-started
+
+	waiterDone := make(chan []byte, 1)
+	go func() {
+		body, err := g.do(context.Background(), "plant/list", func() ([]byte, error) {
+			atomic.AddInt32(&calls, 1)
+			return []byte("ok"), nil
+		})
+		if err != nil {
+			t.Errorf("waiter: unexpected error: %v", err)
+		}
+		waiterDone <- body
+	}()
+
+	// Give the waiter time to join the flight before the leader is cancelled
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderDone; err != context.Canceled {
+		t.Errorf("leader: expected context.Canceled, got %v", err)
+	}
+	if body := <-waiterDone; string(body) != "ok" {
+		t.Errorf("waiter: expected its own retry to succeed, got %q", body)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected the waiter to retry once, got %d calls", n)
+	}
+}
+
+func TestFlightGroup_PanicReleasesWaiters(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		g.do(context.Background(), "plant/list", func() ([]byte, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	waiterDone := make(chan error, 1)
+	go func() {
+		_, err := g.do(context.Background(), "plant/list", func() ([]byte, error) {
+			return []byte("ok"), nil
+		})
+		waiterDone <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-waiterDone:
+		if err != nil && err != errFlightPanicked {
+			t.Errorf("expected errFlightPanicked or a fresh result, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after the shared call panicked")
+	}
+
+	// The key is free again
+	if body, err := g.do(context.Background(), "plant/list", func() ([]byte, error) { return []byte("ok"), nil }); err != nil || string(body) != "ok" {
+		t.Errorf("expected a new call after the panic, got %q, %v", body, err)
+	}
+}
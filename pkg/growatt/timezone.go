@@ -0,0 +1,42 @@
+package growatt
+
+import (
+	"fmt"
+	"time"
+)
+
+// timezoneAliases maps legacy zone names to their canonical IANA names, which
+// minimal tzdata installations (e.g. slim container images) may ship alone
+var timezoneAliases = map[string]string{
+	"US/Alaska":   "America/Anchorage",
+	"US/Arizona":  "America/Phoenix",
+	"US/Central":  "America/Chicago",
+	"US/Eastern":  "America/New_York",
+	"US/Hawaii":   "Pacific/Honolulu",
+	"US/Mountain": "America/Denver",
+	"US/Pacific":  "America/Los_Angeles",
+	"GB":          "Europe/London",
+	"PRC":         "Asia/Shanghai",
+}
+
+// ResolveTimezone loads a timezone by name, falling back to the canonical
+// IANA name when a legacy alias such as US/Central is not installed
+func ResolveTimezone(name string) (*time.Location, error) {
+	return resolveTimezone(name, time.LoadLocation)
+}
+
+// resolveTimezone implements ResolveTimezone with a pluggable loader
+func resolveTimezone(name string, load func(string) (*time.Location, error)) (*time.Location, error) {
+	loc, err := load(name)
+	if err == nil {
+		return loc, nil
+	}
+
+	if canonical, ok := timezoneAliases[name]; ok {
+		if loc, aliasErr := load(canonical); aliasErr == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("loading timezone %q: %w", name, err)
+}
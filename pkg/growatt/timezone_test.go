@@ -0,0 +1,58 @@
+package growatt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolveTimezone(t *testing.T) {
+	// Simulate a minimal tzdata install that only ships canonical names
+	installed := map[string]bool{"America/Chicago": true, "UTC": true}
+	load := func(name string) (*time.Location, error) {
+		if !installed[name] {
+			return nil, errors.New("unknown time zone " + name)
+		}
+		return time.FixedZone(name, 0), nil
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"canonical", "America/Chicago", "America/Chicago", false},
+		{"alias", "US/Central", "America/Chicago", false},
+		{"utc", "UTC", "UTC", false},
+		{"alias target missing", "US/Pacific", "", true},
+		{"unknown", "Mars/Olympus_Mons", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := resolveTimezone(tt.input, load)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if loc.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, loc.String())
+			}
+		})
+	}
+}
+
+func TestResolveTimezone_System(t *testing.T) {
+	if _, err := ResolveTimezone("US/Central"); err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	if _, err := ResolveTimezone("Not/A_Zone"); err == nil {
+		t.Error("expected error for unknown zone")
+	}
+}
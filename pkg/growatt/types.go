@@ -2,6 +2,7 @@ package growatt
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -79,19 +80,79 @@ func (s FlexString) String() string {
 	return string(s)
 }
 
+// FlexInt handles JSON integers that may be strings or numbers
+type FlexInt int
+
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	// Handle null
+	if string(data) == "null" {
+		*i = 0
+		return nil
+	}
+
+	// Try as number first
+	var num int
+	if err := json.Unmarshal(data, &num); err == nil {
+		*i = FlexInt(num)
+		return nil
+	}
+
+	// Try as string
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	if str == "" {
+		*i = 0
+		return nil
+	}
+
+	num, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil {
+		return err
+	}
+	*i = FlexInt(num)
+	return nil
+}
+
+func (i FlexInt) Int() int {
+	return int(i)
+}
+
 // TimeUnit represents the time unit for energy queries
 type TimeUnit string
 
 const (
 	TimeUnitDay   TimeUnit = "day"
 	TimeUnitMonth TimeUnit = "month"
+	TimeUnitYear  TimeUnit = "year"
+)
+
+// PowerUnit is the unit a power value is reported in
+type PowerUnit string
+
+const (
+	PowerUnitW  PowerUnit = "W"
+	PowerUnitKW PowerUnit = "kW"
 )
 
+// Convert returns v, given in unit u, expressed in unit to
+func (u PowerUnit) Convert(v float64, to PowerUnit) float64 {
+	switch {
+	case u == PowerUnitW && to == PowerUnitKW:
+		return v / 1000
+	case u == PowerUnitKW && to == PowerUnitW:
+		return v * 1000
+	}
+	return v
+}
+
 // Response is the generic API response wrapper
 type Response[T any] struct {
-	ErrorCode int    `json:"error_code"`
-	ErrorMsg  string `json:"error_msg"`
-	Data      T      `json:"data"`
+	ErrorCode FlexInt `json:"error_code"`
+	ErrorMsg  string  `json:"error_msg"`
+	Data      T       `json:"data"`
 }
 
 // Plant represents a power station
@@ -103,10 +164,10 @@ type Plant struct {
 	City          string     `json:"city"`
 	Latitude      FlexFloat  `json:"latitude"`
 	Longitude     FlexFloat  `json:"longitude"`
-	PeakPower     FlexFloat  `json:"peak_power"`
-	CurrentPower  FlexFloat  `json:"current_power"`
-	TodayEnergy   FlexFloat  `json:"today_energy"`
-	TotalEnergy   FlexFloat  `json:"total_energy"`
+	PeakPower     FlexFloat  `json:"peak_power"`    // Installed peak power (kW)
+	CurrentPower  FlexFloat  `json:"current_power"` // W
+	TodayEnergy   FlexFloat  `json:"today_energy"`  // kWh
+	TotalEnergy   FlexFloat  `json:"total_energy"`  // kWh
 	CreateDate    string     `json:"create_date"`
 	Status        int        `json:"status"`
 	FormulaCoal   FlexFloat  `json:"formula_coal"`
@@ -117,6 +178,72 @@ type Plant struct {
 	MoneyUnitText string     `json:"money_unit_text"`
 }
 
+// Native units of the Plant power fields
+const (
+	PlantCurrentPowerUnit = PowerUnitW
+	PlantPeakPowerUnit    = PowerUnitKW
+)
+
+// CurrentPowerW returns the plant's current power in watts
+func (p Plant) CurrentPowerW() float64 {
+	return PlantCurrentPowerUnit.Convert(p.CurrentPower.Float64(), PowerUnitW)
+}
+
+// CurrentPowerKW returns the plant's current power in kilowatts
+func (p Plant) CurrentPowerKW() float64 {
+	return PlantCurrentPowerUnit.Convert(p.CurrentPower.Float64(), PowerUnitKW)
+}
+
+// PeakPowerW returns the plant's installed peak power in watts
+func (p Plant) PeakPowerW() float64 {
+	return PlantPeakPowerUnit.Convert(p.PeakPower.Float64(), PowerUnitW)
+}
+
+// PeakPowerKW returns the plant's installed peak power in kilowatts
+func (p Plant) PeakPowerKW() float64 {
+	return PlantPeakPowerUnit.Convert(p.PeakPower.Float64(), PowerUnitKW)
+}
+
+// EnvironmentalImpact is the lifetime environmental savings of a plant
+type EnvironmentalImpact struct {
+	CO2Kg           float64 `json:"co2_kg"`
+	CoalKg          float64 `json:"coal_kg"`
+	TreesEquivalent float64 `json:"trees_equivalent"`
+}
+
+// EnvironmentalImpact returns the plant's savings, computed from TotalEnergy
+// and the per-kWh Formula* coefficients reported by the API
+func (p Plant) EnvironmentalImpact() EnvironmentalImpact {
+	total := p.TotalEnergy.Float64()
+	return EnvironmentalImpact{
+		CO2Kg:           total * p.FormulaCO2.Float64(),
+		CoalKg:          total * p.FormulaCoal.Float64(),
+		TreesEquivalent: total * p.FormulaTree.Float64(),
+	}
+}
+
+// PlantDeviceSummary is the device overview nested in plant details
+type PlantDeviceSummary struct {
+	DeviceCount       int       `json:"device_count"`
+	InstalledCapacity FlexFloat `json:"installed_capacity"` // kW
+}
+
+// PlantDetails is the plant/details response, which adds a device summary
+// to the fields returned by the plant list
+type PlantDetails struct {
+	Plant
+	DeviceSummary PlantDeviceSummary `json:"device_summary"`
+}
+
+// PlantStorage is the battery/storage overview of a plant
+type PlantStorage struct {
+	PlantID        FlexString `json:"plant_id"`
+	Soc            FlexFloat  `json:"soc"`             // State of charge (%)
+	ChargePower    FlexFloat  `json:"charge_power"`    // W
+	DischargePower FlexFloat  `json:"discharge_power"` // W
+	Capacity       FlexFloat  `json:"capacity"`        // kWh
+}
+
 // PlantListData is the response data for plant list
 type PlantListData struct {
 	Count  int     `json:"count"`
@@ -126,12 +253,25 @@ type PlantListData struct {
 // PlantData represents energy overview data
 type PlantData struct {
 	PlantID        FlexString `json:"plant_id"`
-	TodayEnergy    FlexFloat  `json:"today_energy"`
-	TotalEnergy    FlexFloat  `json:"total_energy"`
-	CurrentPower   FlexFloat  `json:"current_power"`
-	PeakPowerToday FlexFloat  `json:"peak_power_today"`
-	MonthEnergy    FlexFloat  `json:"month_energy"`
-	YearEnergy     FlexFloat  `json:"year_energy"`
+	TodayEnergy    FlexFloat  `json:"today_energy"`     // kWh
+	TotalEnergy    FlexFloat  `json:"total_energy"`     // kWh
+	CurrentPower   FlexFloat  `json:"current_power"`    // W
+	PeakPowerToday FlexFloat  `json:"peak_power_today"` // W
+	MonthEnergy    FlexFloat  `json:"month_energy"`     // kWh
+	YearEnergy     FlexFloat  `json:"year_energy"`      // kWh
+}
+
+// PlantDataPowerUnit is the native unit of the PlantData power fields
+const PlantDataPowerUnit = PowerUnitW
+
+// CurrentPowerW returns the current power in watts
+func (d PlantData) CurrentPowerW() float64 {
+	return PlantDataPowerUnit.Convert(d.CurrentPower.Float64(), PowerUnitW)
+}
+
+// CurrentPowerKW returns the current power in kilowatts
+func (d PlantData) CurrentPowerKW() float64 {
+	return PlantDataPowerUnit.Convert(d.CurrentPower.Float64(), PowerUnitKW)
 }
 
 // PowerDataPoint represents a single 5-minute power reading
@@ -151,21 +291,63 @@ type PowerData struct {
 type PowerDataRaw struct {
 	PlantID FlexString `json:"plant_id"`
 	Count   int        `json:"count"`
-	Powers  FlexPowers `json:"powers"`
+	Powers  RawPowers  `json:"powers"`
+}
+
+// UnmarshalJSON reads the readings from "powers" or, on accounts that nest
+// them, from "datas.powers"
+func (p *PowerDataRaw) UnmarshalJSON(data []byte) error {
+	type plain PowerDataRaw
+	var result plain
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	if len(result.Powers) == 0 {
+		var nested struct {
+			Datas *plain `json:"datas"`
+		}
+		if err := json.Unmarshal(data, &nested); err == nil && nested.Datas != nil {
+			result.Powers = nested.Datas.Powers
+			if result.Count == 0 {
+				result.Count = nested.Datas.Count
+			}
+			if result.PlantID == "" {
+				result.PlantID = nested.Datas.PlantID
+			}
+		}
+	}
+
+	*p = PowerDataRaw(result)
+	return nil
 }
 
 // FlexPowers handles powers data that may be a map or an array
 type FlexPowers map[string]float64
 
 func (p *FlexPowers) UnmarshalJSON(data []byte) error {
+	var raw RawPowers
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	result := make(map[string]float64, len(raw))
+	for timeStr, power := range raw {
+		result[normalizeTime(timeStr)] = power
+	}
+	*p = FlexPowers(result)
+	return nil
+}
+
+// RawPowers handles powers data that may be a map or an array, keeping time
+// keys exactly as returned by the API
+type RawPowers map[string]float64
+
+func (p *RawPowers) UnmarshalJSON(data []byte) error {
 	// Try as map first (original expected format)
 	var m map[string]float64
 	if err := json.Unmarshal(data, &m); err == nil {
-		result := make(map[string]float64)
-		for timeStr, power := range m {
-			result[normalizeTime(timeStr)] = power
-		}
-		*p = FlexPowers(result)
+		*p = RawPowers(m)
 		return nil
 	}
 
@@ -177,9 +359,9 @@ func (p *FlexPowers) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &arr); err == nil {
 		result := make(map[string]float64)
 		for _, item := range arr {
-			result[normalizeTime(item.Time)] = item.Power.Float64()
+			result[item.Time] = item.Power.Float64()
 		}
-		*p = FlexPowers(result)
+		*p = RawPowers(result)
 		return nil
 	}
 
@@ -194,11 +376,11 @@ func (p *FlexPowers) UnmarshalJSON(data []byte) error {
 				json.Unmarshal(item[0], &timeStr)
 				json.Unmarshal(item[1], &power)
 				if timeStr != "" {
-					result[normalizeTime(timeStr)] = power
+					result[timeStr] = power
 				}
 			}
 		}
-		*p = FlexPowers(result)
+		*p = RawPowers(result)
 		return nil
 	}
 
@@ -207,7 +389,7 @@ func (p *FlexPowers) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// normalizeTime extracts HH:MM from various time formats
+// normalizeTime extracts a zero-padded HH:MM from various time formats
 func normalizeTime(t string) string {
 	// Handle "YYYY-MM-DD HH:MM" or "YYYY-MM-DD HH:MM:SS"
 	if strings.Contains(t, " ") {
@@ -216,11 +398,21 @@ func normalizeTime(t string) string {
 			t = parts[1]
 		}
 	}
-	// Truncate seconds if present (HH:MM:SS -> HH:MM)
-	if len(t) > 5 && t[2] == ':' && t[5] == ':' {
-		t = t[:5]
+
+	// Zero-pad "H:MM" and drop seconds so keys sort correctly as strings
+	parts := strings.Split(t, ":")
+	if len(parts) < 2 {
+		return t
 	}
-	return t
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return t
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return t
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
 }
 
 // EnergyDataPoint represents energy data for a time period
@@ -252,12 +444,116 @@ type Device struct {
 	LastUpdate string     `json:"last_update"`
 }
 
+// DeviceKind identifies the category of a device
+type DeviceKind string
+
+const (
+	DeviceKindInverter DeviceKind = "inverter"
+	DeviceKindStorage  DeviceKind = "storage"
+	DeviceKindOther    DeviceKind = "other"
+	DeviceKindMAX      DeviceKind = "max"
+	DeviceKindSPH      DeviceKind = "sph"
+	DeviceKindSPA      DeviceKind = "spa"
+	DeviceKindMIN      DeviceKind = "min"
+	DeviceKindPCS      DeviceKind = "pcs"
+	DeviceKindHPS      DeviceKind = "hps"
+	DeviceKindPBD      DeviceKind = "pbd"
+	DeviceKindUnknown  DeviceKind = "unknown"
+)
+
+// deviceKinds maps the API device_type codes to kinds
+var deviceKinds = map[int]DeviceKind{
+	1:  DeviceKindInverter,
+	2:  DeviceKindStorage,
+	3:  DeviceKindOther,
+	4:  DeviceKindMAX,
+	5:  DeviceKindSPH,
+	6:  DeviceKindSPA,
+	7:  DeviceKindMIN,
+	8:  DeviceKindPCS,
+	9:  DeviceKindHPS,
+	10: DeviceKindPBD,
+}
+
+// Kind returns the device category derived from DeviceType
+func (d Device) Kind() DeviceKind {
+	if kind, ok := deviceKinds[d.DeviceType]; ok {
+		return kind
+	}
+	return DeviceKindUnknown
+}
+
 // DeviceListData is the response data for device list
 type DeviceListData struct {
 	Count   int      `json:"count"`
 	Devices []Device `json:"devices"`
 }
 
+// deviceListKeys are the keys some accounts use instead of "devices"
+var deviceListKeys = []string{"devices", "inverters", "tlx"}
+
+// UnmarshalJSON accepts devices listed under "devices", "inverters" or "tlx",
+// merging all of them into Devices
+func (d *DeviceListData) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var result DeviceListData
+	if count, ok := raw["count"]; ok {
+		var n FlexFloat
+		if err := json.Unmarshal(count, &n); err != nil {
+			return fmt.Errorf("parsing count: %w", err)
+		}
+		result.Count = int(n.Float64())
+	}
+
+	for _, key := range deviceListKeys {
+		list, ok := raw[key]
+		if !ok {
+			continue
+		}
+		var devices []Device
+		if err := json.Unmarshal(list, &devices); err != nil {
+			return fmt.Errorf("parsing %s: %w", key, err)
+		}
+		result.Devices = append(result.Devices, devices...)
+	}
+
+	*d = result
+	return nil
+}
+
+// SignalPoint is a datalogger signal strength reading
+type SignalPoint struct {
+	Time     string    `json:"time"`
+	Strength FlexFloat `json:"signal"` // dBm
+}
+
+// DataloggerSignalData is the response data for datalogger signal history
+type DataloggerSignalData struct {
+	DatalogSN FlexString    `json:"datalog_sn"`
+	Count     int           `json:"count"`
+	Datas     []SignalPoint `json:"datas"`
+}
+
+// Fault is an alarm or fault event raised by one of a plant's devices
+type Fault struct {
+	DeviceSN  FlexString `json:"device_sn"`
+	Code      FlexString `json:"alarm_code"`
+	Message   string     `json:"alarm_message"`
+	StartTime string     `json:"start_time"`
+	EndTime   string     `json:"end_time"`
+	Status    int        `json:"status"`
+}
+
+// PlantFaultData is one page of plant fault history
+type PlantFaultData struct {
+	Count  int     `json:"count"` // Total faults for the query, across all pages
+	Alarms []Fault `json:"alarms"`
+}
+
 // MINInverterData represents data for MIN/TLX inverters
 type MINInverterData struct {
 	Serial      string    `json:"tlx_sn"`
@@ -272,7 +568,51 @@ type MINInverterData struct {
 	Vac1        FlexFloat `json:"vac1"`
 	Iac1        FlexFloat `json:"iac1"`
 	Fac         FlexFloat `json:"fac"`
+	Pf          FlexFloat `json:"pf"`  // Power factor
+	Qac         FlexFloat `json:"qac"` // Reactive power (var)
 	Temperature FlexFloat `json:"temperature"`
+
+	// Hardware and firmware, empty when the API omits them
+	Model      string    `json:"model"`
+	FwVersion  string    `json:"fw_version"`
+	DSPVersion string    `json:"dsp_version"`
+	ARMVersion string    `json:"arm_version"`
+	RatedPower FlexFloat `json:"rated_power"` // W
+
+	// JSON names of the fields the response carried a value for
+	present map[string]bool
+}
+
+// UnmarshalJSON decodes the inverter data and records which fields were
+// present, so readings the firmware omits can be told apart from zeros
+func (m *MINInverterData) UnmarshalJSON(data []byte) error {
+	type plain MINInverterData
+	var result plain
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	result.present = make(map[string]bool, len(raw))
+	for key, value := range raw {
+		if !isEmptyData(value) {
+			result.present[key] = true
+		}
+	}
+
+	*m = MINInverterData(result)
+	return nil
+}
+
+// Has reports whether the response included a value for the field with the
+// given JSON name, such as "vpv2" or "temperature". Null and empty-string
+// values count as absent. It is false for values not decoded from JSON.
+func (m *MINInverterData) Has(field string) bool {
+	return m.present[field]
 }
 
 // ParsedPowerData is power data with parsed time
@@ -2,6 +2,7 @@ package growatt
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 )
 
@@ -245,6 +246,32 @@ func TestFlexPowers_Empty(t *testing.T) {
 	}
 }
 
+func TestRawPowers_KeepsKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"map", `{"2025-02-03 12:00": 4500.5, "2025-02-03 12:05": 0}`},
+		{"array of objects", `[{"time": "2025-02-03 12:00", "power": 4500.5}, {"time": "2025-02-03 12:05", "power": null}]`},
+		{"array of arrays", `[["2025-02-03 12:00", 4500.5], ["2025-02-03 12:05", 0]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p RawPowers
+			if err := json.Unmarshal([]byte(tt.input), &p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(p) != 2 || p["2025-02-03 12:00"] != 4500.5 {
+				t.Errorf("expected raw keys to be preserved, got %v", p)
+			}
+			if v, ok := p["2025-02-03 12:05"]; !ok || v != 0 {
+				t.Errorf("expected 0 for null power, got %v", p)
+			}
+		})
+	}
+}
+
 func TestPlant_NumericPlantID(t *testing.T) {
 	// Simulates API returning plant_id as a number
 	input := `{
@@ -263,3 +290,146 @@ func TestPlant_NumericPlantID(t *testing.T) {
 		t.Errorf("expected plant_id %q, got %q", "12345", plant.PlantID.String())
 	}
 }
+
+func TestDeviceKind(t *testing.T) {
+	tests := []struct {
+		deviceType int
+		expected   DeviceKind
+	}{
+		{1, DeviceKindInverter},
+		{2, DeviceKindStorage},
+		{4, DeviceKindMAX},
+		{5, DeviceKindSPH},
+		{7, DeviceKindMIN},
+		{0, DeviceKindUnknown},
+		{99, DeviceKindUnknown},
+	}
+
+	for _, tt := range tests {
+		d := Device{DeviceType: tt.deviceType}
+		if d.Kind() != tt.expected {
+			t.Errorf("device type %d: expected kind %q, got %q", tt.deviceType, tt.expected, d.Kind())
+		}
+	}
+}
+
+func TestPlant_EnvironmentalImpact(t *testing.T) {
+	var resp Response[PlantListData]
+	if err := json.Unmarshal(loadTestData(t, "plant_list.json"), &resp); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	impact := resp.Data.Plants[0].EnvironmentalImpact()
+
+	// 15234.8 kWh total energy
+	if math.Abs(impact.CO2Kg-15189.0956) > 0.001 {
+		t.Errorf("expected CO2 15189.0956 kg, got %f", impact.CO2Kg)
+	}
+	if math.Abs(impact.CoalKg-6093.92) > 0.001 {
+		t.Errorf("expected coal 6093.92 kg, got %f", impact.CoalKg)
+	}
+	if math.Abs(impact.TreesEquivalent-822.6792) > 0.001 {
+		t.Errorf("expected 822.6792 trees, got %f", impact.TreesEquivalent)
+	}
+
+	// Plant without coefficients reports no savings
+	impact = resp.Data.Plants[1].EnvironmentalImpact()
+	if impact != (EnvironmentalImpact{}) {
+		t.Errorf("expected zero impact without coefficients, got %+v", impact)
+	}
+}
+
+func TestNormalizeTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"12:00", "12:00"},
+		{"9:05", "09:05"},
+		{"9:05:30", "09:05"},
+		{"0:00", "00:00"},
+		{"2025-02-03 9:00", "09:00"},
+		{"2025-02-03 12:05:00", "12:05"},
+		{"bogus", "bogus"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeTime(tt.input); got != tt.expected {
+			t.Errorf("normalizeTime(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestPowerUnit_Convert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to PowerUnit
+		expected float64
+	}{
+		{4523.5, PowerUnitW, PowerUnitKW, 4.5235},
+		{9.6, PowerUnitKW, PowerUnitW, 9600},
+		{4523.5, PowerUnitW, PowerUnitW, 4523.5},
+		{9.6, PowerUnitKW, PowerUnitKW, 9.6},
+	}
+
+	for _, tt := range tests {
+		got := tt.from.Convert(tt.value, tt.to)
+		if math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%v %s -> %s: expected %v, got %v", tt.value, tt.from, tt.to, tt.expected, got)
+		}
+	}
+}
+
+func TestPlant_PowerAccessors(t *testing.T) {
+	p := Plant{CurrentPower: 4523.5, PeakPower: 9.6}
+
+	if got := p.CurrentPowerW(); got != 4523.5 {
+		t.Errorf("CurrentPowerW: expected 4523.5, got %v", got)
+	}
+	if got := p.CurrentPowerKW(); math.Abs(got-4.5235) > 1e-9 {
+		t.Errorf("CurrentPowerKW: expected 4.5235, got %v", got)
+	}
+	if got := p.PeakPowerW(); math.Abs(got-9600) > 1e-9 {
+		t.Errorf("PeakPowerW: expected 9600, got %v", got)
+	}
+	if got := p.PeakPowerKW(); got != 9.6 {
+		t.Errorf("PeakPowerKW: expected 9.6, got %v", got)
+	}
+
+	d := PlantData{CurrentPower: 2100}
+	if got := d.CurrentPowerW(); got != 2100 {
+		t.Errorf("PlantData CurrentPowerW: expected 2100, got %v", got)
+	}
+	if got := d.CurrentPowerKW(); math.Abs(got-2.1) > 1e-9 {
+		t.Errorf("PlantData CurrentPowerKW: expected 2.1, got %v", got)
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{`10011`, 10011},
+		{`"10011"`, 10011},
+		{`"0"`, 0},
+		{`""`, 0},
+		{`null`, 0},
+	}
+
+	for _, tt := range tests {
+		var i FlexInt
+		if err := json.Unmarshal([]byte(tt.input), &i); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if i.Int() != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.input, tt.expected, i.Int())
+		}
+	}
+
+	var i FlexInt
+	if err := json.Unmarshal([]byte(`"abc"`), &i); err == nil {
+		t.Error("expected error for non-numeric string")
+	}
+}